@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"tagmyhead/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxDeckUpload предел тела запроса при импорте колоды
+const maxDeckUpload = 2 << 20
+
+type DeckRequest struct {
+	Name    string             `json:"name"`
+	Entries []models.DeckEntry `json:"entries"`
+}
+
+type DeckSummary struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Size       int      `json:"size"`
+	Categories []string `json:"categories"`
+	Languages  []string `json:"languages"`
+	ReadOnly   bool     `json:"readOnly"`
+}
+
+// distinct непустые значения поля карточек в порядке появления
+func distinct(entries []models.DeckEntry, field func(models.DeckEntry) string) []string {
+	seen := make(map[string]bool)
+	values := make([]string, 0)
+	for _, entry := range entries {
+		value := field(entry)
+		if value == "" || seen[strings.ToLower(value)] {
+			continue
+		}
+		seen[strings.ToLower(value)] = true
+		values = append(values, value)
+	}
+	return values
+}
+
+func summarizeDeck(deck *models.Deck) DeckSummary {
+	return DeckSummary{
+		ID:   deck.ID,
+		Name: deck.Name,
+		Size: len(deck.Entries),
+		Categories: distinct(deck.Entries, func(e models.DeckEntry) string {
+			return e.Category
+		}),
+		Languages: distinct(deck.Entries, func(e models.DeckEntry) string {
+			return e.Language
+		}),
+		ReadOnly: deck.ReadOnly,
+	}
+}
+
+// deckAuthor кто меняет колоду: администратор или игрок комнаты
+type deckAuthor struct {
+	playerID string
+	admin    bool
+}
+
+// owns может ли автор менять и удалять колоду
+func (a deckAuthor) owns(deck *models.Deck) bool {
+	return a.admin || (deck.Owner != "" && deck.Owner == a.playerID)
+}
+
+// authorizeDeckWrite проверяет токен администратора или сессию игрока
+// комнаты ?code= с ?playerId=. Колоды пишут только участники игр.
+func authorizeDeckWrite(c echo.Context) (deckAuthor, bool) {
+	token := sessionToken(c)
+	if isAdminToken(token) {
+		return deckAuthor{admin: true}, true
+	}
+
+	playerID := c.QueryParam("playerId")
+	room, exists := models.GetRoom(c.QueryParam("code"))
+	if !exists || !room.VerifySession(playerID, token) {
+		return deckAuthor{}, false
+	}
+	return deckAuthor{playerID: playerID}, true
+}
+
+func unauthorizedDeckWrite(c echo.Context) error {
+	return c.JSON(http.StatusUnauthorized, map[string]string{
+		"error": "Invalid session token",
+	})
+}
+
+// readOnlyDeck колоды из DECKS_DIR меняются только правкой файлов
+func readOnlyDeck(c echo.Context, deck *models.Deck) error {
+	return c.JSON(http.StatusForbidden, models.NewErrorResponse(&models.CommandError{
+		Code:    models.ErrCodeForbidden,
+		Message: "deck " + deck.ID + " is read-only",
+	}))
+}
+
+func notDeckOwner(c echo.Context) error {
+	return c.JSON(http.StatusForbidden, models.NewErrorResponse(&models.CommandError{
+		Code:    models.ErrCodeForbidden,
+		Message: "only the owner can change this deck",
+	}))
+}
+
+// deckError ошибки проверки колоды отдаются клиенту, остальные считаются
+// ошибками хранилища
+func deckError(c echo.Context, err error) error {
+	var cmdErr *models.CommandError
+	if !errors.As(err, &cmdErr) {
+		c.Logger().Errorf("Failed to store deck: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to store deck",
+		})
+	}
+	switch cmdErr.Code {
+	case models.ErrCodeForbidden:
+		return c.JSON(http.StatusForbidden, models.NewErrorResponse(err))
+	case models.ErrCodeDeckInUse:
+		return c.JSON(http.StatusConflict, models.NewErrorResponse(err))
+	}
+	return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err))
+}
+
+// GET /api/decks
+func ListDecks(c echo.Context) error {
+	decks := models.Decks()
+	summaries := make([]DeckSummary, 0, len(decks))
+	for _, deck := range decks {
+		summaries = append(summaries, summarizeDeck(deck))
+	}
+	return c.JSON(http.StatusOK, summaries)
+}
+
+// GET /api/decks/:id
+func GetDeck(c echo.Context) error {
+	deck, exists := models.GetDeck(c.Param("id"))
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Deck not found",
+		})
+	}
+	return c.JSON(http.StatusOK, deck)
+}
+
+// POST /api/decks?code=&playerId=
+// Создатель становится владельцем колоды
+func CreateDeck(c echo.Context) error {
+	author, ok := authorizeDeckWrite(c)
+	if !ok {
+		return unauthorizedDeckWrite(c)
+	}
+
+	var req DeckRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request",
+		})
+	}
+
+	deck := &models.Deck{
+		ID:      models.NewDeckID(),
+		Name:    req.Name,
+		Entries: req.Entries,
+		Owner:   author.playerID,
+	}
+	if err := models.SaveDeck(deck); err != nil {
+		return deckError(c, err)
+	}
+	return c.JSON(http.StatusCreated, deck)
+}
+
+// PUT /api/decks/:id?code=&playerId=
+// Заменяет название и все карточки колоды, доступно только владельцу
+func UpdateDeck(c echo.Context) error {
+	author, ok := authorizeDeckWrite(c)
+	if !ok {
+		return unauthorizedDeckWrite(c)
+	}
+
+	id := c.Param("id")
+	existing, exists := models.GetDeck(id)
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Deck not found",
+		})
+	}
+	if existing.ReadOnly {
+		return readOnlyDeck(c, existing)
+	}
+	if !author.owns(existing) {
+		return notDeckOwner(c)
+	}
+
+	var req DeckRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request",
+		})
+	}
+
+	deck := &models.Deck{
+		ID:      id,
+		Name:    req.Name,
+		Entries: req.Entries,
+		Owner:   existing.Owner,
+	}
+	if err := models.SaveDeck(deck); err != nil {
+		return deckError(c, err)
+	}
+	return c.JSON(http.StatusOK, deck)
+}
+
+// DELETE /api/decks/:id?code=&playerId=
+// Доступно только владельцу. Колоду, выбранную в комнате, удалить нельзя.
+func DeleteDeck(c echo.Context) error {
+	author, ok := authorizeDeckWrite(c)
+	if !ok {
+		return unauthorizedDeckWrite(c)
+	}
+
+	id := c.Param("id")
+	if deck, exists := models.GetDeck(id); exists {
+		if deck.ReadOnly {
+			return readOnlyDeck(c, deck)
+		}
+		if !author.owns(deck) {
+			return notDeckOwner(c)
+		}
+	}
+
+	deleted, err := models.DeleteDeck(id)
+	if err != nil {
+		return deckError(c, err)
+	}
+	if !deleted {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Deck not found",
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// deckFormat формат из ?format=, иначе по Content-Type, по умолчанию json
+func deckFormat(c echo.Context) string {
+	if format := c.QueryParam("format"); format != "" {
+		return strings.ToLower(format)
+	}
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return "csv"
+	case strings.Contains(contentType, "yaml"):
+		return "yaml"
+	}
+	return "json"
+}
+
+// POST /api/decks/import?format=csv|json|yaml&name=&code=&playerId=
+// Тело запроса файл колоды в том же формате, что и в DECKS_DIR
+func ImportDeck(c echo.Context) error {
+	author, ok := authorizeDeckWrite(c)
+	if !ok {
+		return unauthorizedDeckWrite(c)
+	}
+
+	format := deckFormat(c)
+	switch format {
+	case "csv", "json", "yaml", "yml":
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "format must be csv, json or yaml",
+		})
+	}
+
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, maxDeckUpload)
+	deck, err := models.ParseDeck(format, body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid deck: " + err.Error(),
+		})
+	}
+
+	deck.ID = models.NewDeckID()
+	deck.Owner = author.playerID
+	if name := c.QueryParam("name"); name != "" {
+		deck.Name = name
+	}
+	if err := models.SaveDeck(deck); err != nil {
+		return deckError(c, err)
+	}
+	return c.JSON(http.StatusCreated, summarizeDeck(deck))
+}
+
+// GET /api/decks/:id/export?format=csv|json
+func ExportDeck(c echo.Context) error {
+	deck, exists := models.GetDeck(c.Param("id"))
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Deck not found",
+		})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "json"
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+deck.ID+`.json"`)
+		return c.JSON(http.StatusOK, deck)
+	case "csv":
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+deck.ID+`.csv"`)
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		c.Response().WriteHeader(http.StatusOK)
+		return deck.WriteCSV(c.Response())
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "format must be csv or json",
+		})
+	}
+}
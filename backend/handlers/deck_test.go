@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"tagmyhead/models"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func deckServer() *echo.Echo {
+	e := echo.New()
+	e.POST("/api/decks", CreateDeck)
+	e.POST("/api/decks/import", ImportDeck)
+	e.GET("/api/decks/:id", GetDeck)
+	e.PUT("/api/decks/:id", UpdateDeck)
+	e.DELETE("/api/decks/:id", DeleteDeck)
+	e.GET("/api/decks/:id/export", ExportDeck)
+	return e
+}
+
+// deckSession игрок новой комнаты, от имени которого пишутся колоды
+type deckSession struct {
+	code, playerID, token string
+}
+
+func newDeckSession(t *testing.T, name string) deckSession {
+	t.Helper()
+	room, err := models.CreateRoom(models.RoomSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { models.DeleteRoom(room.Code) })
+
+	player, err := room.AddPlayer(name, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return deckSession{code: room.Code, playerID: player.ID, token: room.SessionToken(player.ID)}
+}
+
+// query параметры сессии для пути, пустая сессия запрос без авторизации
+func (s deckSession) query(extra url.Values) string {
+	values := url.Values{}
+	for key, value := range extra {
+		values[key] = value
+	}
+	if s.code != "" {
+		values.Set("code", s.code)
+		values.Set("playerId", s.playerID)
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+func (s deckSession) do(e *echo.Echo, method, path string, extra url.Values, contentType, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path+s.query(extra), reader)
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	if s.token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+s.token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeDeck(t *testing.T, rec *httptest.ResponseRecorder) models.Deck {
+	t.Helper()
+	var deck models.Deck
+	if err := json.Unmarshal(rec.Body.Bytes(), &deck); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	return deck
+}
+
+const testDeckBody = `{"name":"Jedi","entries":[{"name":"Yoda","aliases":["Master Yoda"]},{"name":"Obi-Wan Kenobi"}]}`
+
+func TestDeckLifecycle(t *testing.T) {
+	e := deckServer()
+	alice, anonymous := newDeckSession(t, "alice"), deckSession{}
+
+	rec := alice.do(e, http.MethodPost, "/api/decks", nil, echo.MIMEApplicationJSON, testDeckBody)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	created := decodeDeck(t, rec)
+	if created.Owner != alice.playerID || len(created.Entries) != 2 {
+		t.Fatalf("created deck = %+v", created)
+	}
+	path := "/api/decks/" + created.ID
+
+	rec = alice.do(e, http.MethodPut, path, nil, echo.MIMEApplicationJSON, `{"name":"Sith","entries":[{"name":"Darth Vader"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d, body %s", rec.Code, rec.Body)
+	}
+	if updated := decodeDeck(t, rec); updated.Name != "Sith" || updated.Owner != alice.playerID {
+		t.Fatalf("updated deck = %+v", updated)
+	}
+
+	rec = anonymous.do(e, http.MethodGet, path+"/export", url.Values{"format": {"csv"}}, "", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Darth Vader") {
+		t.Fatalf("export csv: status %d, body %s", rec.Code, rec.Body)
+	}
+	rec = anonymous.do(e, http.MethodGet, path+"/export", nil, "", "")
+	if exported := decodeDeck(t, rec); exported.Name != "Sith" {
+		t.Fatalf("export json = %+v", exported)
+	}
+
+	if rec := alice.do(e, http.MethodDelete, path, nil, "", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := anonymous.do(e, http.MethodGet, path, nil, "", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("get after delete: status %d", rec.Code)
+	}
+}
+
+func TestDeckOwnership(t *testing.T) {
+	e := deckServer()
+	alice, bob := newDeckSession(t, "alice"), newDeckSession(t, "bob")
+	anonymous := deckSession{}
+
+	if rec := anonymous.do(e, http.MethodPost, "/api/decks", nil, echo.MIMEApplicationJSON, testDeckBody); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("create without a session: status %d", rec.Code)
+	}
+	forged := deckSession{code: alice.code, playerID: alice.playerID, token: bob.token}
+	if rec := forged.do(e, http.MethodPost, "/api/decks", nil, echo.MIMEApplicationJSON, testDeckBody); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("create with another player's token: status %d", rec.Code)
+	}
+
+	rec := alice.do(e, http.MethodPost, "/api/decks", nil, echo.MIMEApplicationJSON, testDeckBody)
+	path := "/api/decks/" + decodeDeck(t, rec).ID
+
+	if rec := bob.do(e, http.MethodPut, path, nil, echo.MIMEApplicationJSON, testDeckBody); rec.Code != http.StatusForbidden {
+		t.Fatalf("update by another player: status %d", rec.Code)
+	}
+	if rec := bob.do(e, http.MethodDelete, path, nil, "", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete by another player: status %d", rec.Code)
+	}
+
+	SetAdminToken("admin-secret")
+	t.Cleanup(func() { SetAdminToken("") })
+	admin := deckSession{token: "admin-secret"}
+	if rec := admin.do(e, http.MethodPut, path, nil, echo.MIMEApplicationJSON, testDeckBody); rec.Code != http.StatusOK {
+		t.Fatalf("update by admin: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := admin.do(e, http.MethodDelete, path, nil, "", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete by admin: status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestReadOnlyDeck(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "handlers-readonly.yaml"), []byte("name: Files\nentries:\n  - name: Yoda\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := models.LoadDecks(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	e := deckServer()
+	SetAdminToken("admin-secret")
+	t.Cleanup(func() { SetAdminToken("") })
+	// Даже администратор не меняет колоды из файлов
+	admin := deckSession{token: "admin-secret"}
+	path := "/api/decks/handlers-readonly"
+
+	if rec := admin.do(e, http.MethodPut, path, nil, echo.MIMEApplicationJSON, testDeckBody); rec.Code != http.StatusForbidden {
+		t.Fatalf("update of a file deck: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := admin.do(e, http.MethodDelete, path, nil, "", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete of a file deck: status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestImportDeck(t *testing.T) {
+	e := deckServer()
+	alice := newDeckSession(t, "alice")
+
+	tests := []struct {
+		name        string
+		format      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantSize    int
+	}{
+		{"json", "json", "", testDeckBody, http.StatusCreated, 2},
+		{"csv by content type", "", "text/csv", "name,aliases\nYoda,Master Yoda|Yoda\nLuke Skywalker,\n", http.StatusCreated, 2},
+		{"yaml", "yaml", "", "- name: Yoda\n- name: Leia\n- name: Han Solo\n", http.StatusCreated, 3},
+		{"yaml by content type", "", "application/yaml", "name: Droids\nentries:\n  - name: R2-D2\n", http.StatusCreated, 1},
+		{"unknown format", "xml", "", "<deck/>", http.StatusBadRequest, 0},
+		{"broken deck", "json", "", `{"entries":[]}`, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extra := url.Values{"name": {"Imported " + tt.name}}
+			if tt.format != "" {
+				extra.Set("format", tt.format)
+			}
+			rec := alice.do(e, http.MethodPost, "/api/decks/import", extra, tt.contentType, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var summary DeckSummary
+			if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+				t.Fatal(err)
+			}
+			if summary.Size != tt.wantSize || summary.Name != "Imported "+tt.name {
+				t.Fatalf("summary = %+v", summary)
+			}
+			deck, exists := models.GetDeck(summary.ID)
+			if !exists || deck.Owner != alice.playerID {
+				t.Fatalf("imported deck = %+v, want owned by alice", deck)
+			}
+		})
+	}
+}
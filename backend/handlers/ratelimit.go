@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"expvar"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"tagmyhead/models"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// Счётчики отказов, видны в expvar на DEBUG_ADDR
+var rateLimitMetrics = expvar.NewMap("rate_limit")
+
+// AttemptLimiterConfig лимиты для маршрутов, по которым можно перебирать
+// коды комнат и пароли
+type AttemptLimiterConfig struct {
+	// Rate и Burst token bucket запросов с одного IP
+	Rate  rate.Limit
+	Burst int
+
+	// MaxFailures неудачных попыток (404, 401 и отказов по приглашению)
+	// за FailureWindow приводят к блокировке IP
+	MaxFailures   int
+	FailureWindow time.Duration
+
+	// Каждая следующая блокировка вдвое длиннее предыдущей, но не
+	// больше MaxLockout. Счёт блокировок сбрасывается после
+	// LockoutMemory без блокировок.
+	BaseLockout   time.Duration
+	MaxLockout    time.Duration
+	LockoutMemory time.Duration
+
+	// IdleTTL через сколько забывается IP без блокировок в прошлом.
+	// MaxTracked предел числа отслеживаемых IP, при переполнении первыми
+	// забываются давно не заходившие.
+	IdleTTL    time.Duration
+	MaxTracked int
+}
+
+func DefaultAttemptLimiterConfig() AttemptLimiterConfig {
+	return AttemptLimiterConfig{
+		Rate:          5,
+		Burst:         20,
+		MaxFailures:   10,
+		FailureWindow: time.Minute,
+		BaseLockout:   time.Minute,
+		MaxLockout:    time.Hour,
+		LockoutMemory: 24 * time.Hour,
+		IdleTTL:       15 * time.Minute,
+		MaxTracked:    100000,
+	}
+}
+
+// attemptState состояние одного IP
+type attemptState struct {
+	limiter     *rate.Limiter
+	failures    int
+	windowStart time.Time
+	lockouts    int
+	lockedUntil time.Time
+	lastSeen    time.Time
+}
+
+// AttemptLimiter ограничивает частоту запросов с IP и блокирует IP после
+// серии неудачных попыток
+type AttemptLimiter struct {
+	config AttemptLimiterConfig
+
+	mu     sync.Mutex
+	states map[string]*attemptState
+}
+
+func NewAttemptLimiter(config AttemptLimiterConfig) *AttemptLimiter {
+	l := &AttemptLimiter{
+		config: config,
+		states: make(map[string]*attemptState),
+	}
+	go l.cleanup()
+	return l
+}
+
+func (l *AttemptLimiter) stateLocked(ip string, now time.Time) *attemptState {
+	state, exists := l.states[ip]
+	if !exists {
+		if l.config.MaxTracked > 0 && len(l.states) >= l.config.MaxTracked {
+			l.evictLocked(now)
+		}
+		state = &attemptState{
+			limiter: rate.NewLimiter(l.config.Rate, l.config.Burst),
+		}
+		l.states[ip] = state
+	}
+	state.lastSeen = now
+	return state
+}
+
+// allow пропускает запрос или возвращает, через сколько повторить
+func (l *AttemptLimiter) allow(ip string) (bool, string, time.Duration) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := l.stateLocked(ip, now)
+	if now.Before(state.lockedUntil) {
+		return false, "lockout", state.lockedUntil.Sub(now)
+	}
+
+	reservation := state.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		// Токен не тратим, запрос всё равно отклонён
+		reservation.CancelAt(now)
+		return false, "rate", delay
+	}
+	return true, "", 0
+}
+
+// fail учитывает неудачную попытку и при необходимости блокирует IP
+func (l *AttemptLimiter) fail(ip string) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := l.stateLocked(ip, now)
+	if now.Sub(state.windowStart) > l.config.FailureWindow {
+		state.failures = 0
+		state.windowStart = now
+	}
+	state.failures++
+	rateLimitMetrics.Add("failures", 1)
+
+	if state.failures < l.config.MaxFailures {
+		return
+	}
+	if now.Sub(state.lockedUntil) > l.config.LockoutMemory {
+		state.lockouts = 0
+	}
+	lockout := l.config.BaseLockout * time.Duration(1<<min(state.lockouts, 16))
+	state.lockedUntil = now.Add(min(lockout, l.config.MaxLockout))
+	state.lockouts++
+	state.failures = 0
+	rateLimitMetrics.Add("lockouts", 1)
+}
+
+// expired можно ли забыть IP: он не заблокирован и давно не заходил.
+// IP с блокировками помнится LockoutMemory, чтобы следующая была длиннее.
+func (l *AttemptLimiter) expired(state *attemptState, now time.Time) bool {
+	if now.Before(state.lockedUntil) {
+		return false
+	}
+	ttl := l.config.IdleTTL
+	if state.lockouts > 0 {
+		ttl = l.config.LockoutMemory
+	}
+	return now.Sub(state.lastSeen) > ttl
+}
+
+func (l *AttemptLimiter) sweepLocked(now time.Time) {
+	for ip, state := range l.states {
+		if l.expired(state, now) {
+			delete(l.states, ip)
+		}
+	}
+}
+
+// evictLocked освобождает место под новый IP: сначала забываются
+// устаревшие, если их нет, то давно не заходивший незаблокированный IP
+func (l *AttemptLimiter) evictLocked(now time.Time) {
+	l.sweepLocked(now)
+	if len(l.states) < l.config.MaxTracked {
+		return
+	}
+
+	oldest := ""
+	for ip, state := range l.states {
+		if now.Before(state.lockedUntil) {
+			continue
+		}
+		if oldest == "" || state.lastSeen.Before(l.states[oldest].lastSeen) {
+			oldest = ip
+		}
+	}
+	if oldest != "" {
+		delete(l.states, oldest)
+		rateLimitMetrics.Add("evicted", 1)
+	}
+}
+
+// cleanup забывает IP, с которых давно не было запросов
+func (l *AttemptLimiter) cleanup() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		l.mu.Lock()
+		l.sweepLocked(time.Now())
+		l.mu.Unlock()
+	}
+}
+
+// RateLimitResponse тело ответа 429, то же, что у остальных ошибок,
+// плюс время до следующей попытки
+type RateLimitResponse struct {
+	models.WSErrorResponse
+	RetryAfter int `json:"retryAfter"`
+}
+
+// failedAttemptKey ключ в echo.Context, которым обработчик помечает
+// неудачную попытку, не различимую по статусу
+const failedAttemptKey = "failedAttempt"
+
+// markFailedAttempt считает запрос неудачной попыткой, например вход
+// без приглашения или с чужим приглашением
+func markFailedAttempt(c echo.Context) {
+	c.Set(failedAttemptKey, true)
+}
+
+// isFailedAttempt несуществующая комната, чужой игрок, неверный пароль
+// или помеченный обработчиком отказ
+func isFailedAttempt(c echo.Context) bool {
+	if marked, _ := c.Get(failedAttemptKey).(bool); marked {
+		return true
+	}
+	status := c.Response().Status
+	return status == http.StatusNotFound || status == http.StatusUnauthorized
+}
+
+// Middleware отклоняет запросы сверх лимита с 429 и считает неудачные
+// попытки по статусу ответа
+func (l *AttemptLimiter) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ip := c.RealIP()
+
+			if ok, reason, retryAfter := l.allow(ip); !ok {
+				rateLimitMetrics.Add("rejected_"+reason, 1)
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+				return c.JSON(http.StatusTooManyRequests, RateLimitResponse{
+					WSErrorResponse: models.NewErrorResponse(&models.CommandError{
+						Code:    models.ErrCodeRateLimited,
+						Message: "too many requests, try again later",
+					}),
+					RetryAfter: seconds,
+				})
+			}
+
+			err := next(c)
+			if c.Response().Committed && isFailedAttempt(c) {
+				l.fail(ip)
+			}
+			return err
+		}
+	}
+}
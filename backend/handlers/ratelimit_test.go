@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tagmyhead/models"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func testLimiterConfig() AttemptLimiterConfig {
+	return AttemptLimiterConfig{
+		Rate:          1000,
+		Burst:         1000,
+		MaxFailures:   3,
+		FailureWindow: time.Minute,
+		BaseLockout:   time.Minute,
+		MaxLockout:    time.Hour,
+		LockoutMemory: 24 * time.Hour,
+		IdleTTL:       15 * time.Minute,
+		MaxTracked:    100,
+	}
+}
+
+// limitedServer echo с одним маршрутом за лимитером, ответ задаёт handler
+func limitedServer(l *AttemptLimiter, handler echo.HandlerFunc) *echo.Echo {
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.GET("/", handler, l.Middleware())
+	return e
+}
+
+func doRequest(e *echo.Echo, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = ip + ":40000"
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func respond(status int) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.NoContent(status)
+	}
+}
+
+func TestLockoutAfterFailures(t *testing.T) {
+	e := limitedServer(NewAttemptLimiter(testLimiterConfig()), respond(http.StatusNotFound))
+
+	for i := 0; i < 3; i++ {
+		if rec := doRequest(e, "10.0.0.1"); rec.Code != http.StatusNotFound {
+			t.Fatalf("attempt %d: status %d, want 404", i+1, rec.Code)
+		}
+	}
+
+	rec := doRequest(e, "10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status after %d failures = %d, want 429", 3, rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+	var body RateLimitResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != models.ErrCodeRateLimited || body.RetryAfter != 60 {
+		t.Errorf("body = %+v", body)
+	}
+
+	// Блокировка только для этого IP
+	if rec := doRequest(e, "10.0.0.2"); rec.Code != http.StatusNotFound {
+		t.Errorf("other IP: status %d, want 404", rec.Code)
+	}
+}
+
+func TestFailedAttemptStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler echo.HandlerFunc
+		counted bool
+	}{
+		{"not found", respond(http.StatusNotFound), true},
+		{"unauthorized", respond(http.StatusUnauthorized), true},
+		{"ok", respond(http.StatusOK), false},
+		{"bad request", respond(http.StatusBadRequest), false},
+		{"unmarked forbidden", respond(http.StatusForbidden), false},
+		{"marked forbidden", func(c echo.Context) error {
+			markFailedAttempt(c)
+			return c.NoContent(http.StatusForbidden)
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := limitedServer(NewAttemptLimiter(testLimiterConfig()), tt.handler)
+			for i := 0; i < 3; i++ {
+				doRequest(e, "10.0.0.1")
+			}
+			locked := doRequest(e, "10.0.0.1").Code == http.StatusTooManyRequests
+			if locked != tt.counted {
+				t.Errorf("locked out = %v, want %v", locked, tt.counted)
+			}
+		})
+	}
+}
+
+func TestInviteRejectionIsFailedAttempt(t *testing.T) {
+	room, err := models.CreateRoom(models.RoomSettings{InviteOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(room.Close)
+
+	l := NewAttemptLimiter(testLimiterConfig())
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.POST("/api/room/:code/join", JoinRoom, l.Middleware())
+
+	join := func(invite string) int {
+		body := `{"name": "mallory", "invite": "` + invite + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/room/"+room.Code+"/join", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = "10.0.0.1:40000"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, invite := range []string{"", "forged.invite", ""} {
+		if status := join(invite); status != http.StatusForbidden {
+			t.Fatalf("join(%q) = %d, want 403", invite, status)
+		}
+	}
+	if status := join(""); status != http.StatusTooManyRequests {
+		t.Fatalf("join after rejected invites = %d, want 429", status)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	config := testLimiterConfig()
+	config.Rate, config.Burst = 1, 2
+	e := limitedServer(NewAttemptLimiter(config), respond(http.StatusOK))
+
+	for i := 0; i < 2; i++ {
+		if rec := doRequest(e, "10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i+1, rec.Code)
+		}
+	}
+	if rec := doRequest(e, "10.0.0.1"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the burst: status %d, want 429", rec.Code)
+	}
+}
+
+func TestSweepForgetsIdleIPs(t *testing.T) {
+	l := NewAttemptLimiter(testLimiterConfig())
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stateLocked("idle", now.Add(-20*time.Minute))
+	l.stateLocked("recent", now.Add(-time.Minute))
+	l.stateLocked("locked", now.Add(-20*time.Minute)).lockedUntil = now.Add(time.Minute)
+	punished := l.stateLocked("punished", now.Add(-20*time.Minute))
+	punished.lockouts = 1
+
+	l.sweepLocked(now)
+
+	for ip, want := range map[string]bool{
+		"idle":     false,
+		"recent":   true,
+		"locked":   true,
+		"punished": true,
+	} {
+		if _, tracked := l.states[ip]; tracked != want {
+			t.Errorf("%s tracked = %v, want %v", ip, tracked, want)
+		}
+	}
+
+	// Число блокировок помнится LockoutMemory
+	l.sweepLocked(now.Add(24 * time.Hour))
+	if _, tracked := l.states["punished"]; tracked {
+		t.Error("IP with an old lockout is still tracked")
+	}
+}
+
+func TestTrackedIPsAreCapped(t *testing.T) {
+	config := testLimiterConfig()
+	config.MaxTracked = 3
+	l := NewAttemptLimiter(config)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stateLocked("locked", now).lockedUntil = now.Add(time.Hour)
+	for i, ip := range []string{"a", "b", "c", "d", "e"} {
+		l.stateLocked(ip, now.Add(time.Duration(i)*time.Second))
+	}
+
+	if len(l.states) > config.MaxTracked {
+		t.Fatalf("tracking %d IPs, limit %d", len(l.states), config.MaxTracked)
+	}
+	for _, ip := range []string{"locked", "d", "e"} {
+		if _, tracked := l.states[ip]; !tracked {
+			t.Errorf("%s was evicted", ip)
+		}
+	}
+}
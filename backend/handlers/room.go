@@ -1,31 +1,103 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"tagmyhead/models"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// CreateRoomRequest имя создателя и настройки комнаты, см. models.RoomSettings
+type CreateRoomRequest struct {
+	Name string `json:"name"`
+	models.RoomSettings
+}
+
 type CreateRoomResponse struct {
-	Code string `json:"code"`
+	Code   string           `json:"code"`
+	Player JoinRoomResponse `json:"player"`
 }
 
 // POST /api/room/create
+// Создатель сразу входит в комнату и становится хостом. Без имени хостом
+// стал бы тот, кто первым успеет войти по коду.
 func CreateRoom(c echo.Context) error {
-	room := models.CreateRoom()
+	var req CreateRoomRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request",
+		})
+	}
+
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Name is required",
+		})
+	}
+
+	if err := req.RoomSettings.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err))
+	}
+
+	room, err := models.CreateRoom(req.RoomSettings)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create room",
+		})
+	}
+	// В пустой комнате имя всегда свободно
+	player, err := room.AddPlayer(req.Name, false)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err))
+	}
+
 	return c.JSON(http.StatusCreated, CreateRoomResponse{
 		Code: room.Code,
+		Player: JoinRoomResponse{
+			Player: *player,
+			Token:  room.SessionToken(player.ID),
+		},
 	})
 }
 
 type RoomResponse struct {
-	Code       string                 `json:"code"`
-	Players    []models.Player         `json:"players"`
-	Started    bool                   `json:"started"`
-	Characters map[string]string      `json:"characters"`
-	Messages   []interface{}          `json:"messages"`
+	Code          string                          `json:"code"`
+	HostID        string                          `json:"hostId"`
+	Players       []models.Player                 `json:"players"`
+	Presence      map[string]models.PresenceState `json:"presence"`
+	Started       bool                            `json:"started"`
+	Phase         models.GamePhase                `json:"phase"`
+	Characters    map[string]string               `json:"characters"`
+	CharacterInfo map[string]models.CharacterInfo `json:"characterInfo"`
+	Questions     []models.Question               `json:"questions"`
+	Round         int                             `json:"round"`
+	Scoreboard    []models.ScoreEntry             `json:"scoreboard"`
+	Messages      []models.Event                  `json:"messages"`
+	LastSeq       int64                           `json:"lastSeq"`
+
+	Settings       models.RoomSettings `json:"settings"`
+	ActivePlayerID string              `json:"activePlayerId"`
+	TurnRemaining  int64               `json:"turnRemainingMs"`
+	GameRemaining  int64               `json:"gameRemainingMs"`
+	Paused         bool                `json:"paused"`
+	PausedAt       int64               `json:"pausedAt,omitempty"`
+	WrongGuesses   map[string]int      `json:"wrongGuesses"`
+
+	// MessagesTruncated в messages нет части событий после since,
+	// журнал хранит только последние models.MaxEvents
+	MessagesTruncated bool `json:"messagesTruncated"`
+}
+
+// sinceParam номер последнего полученного клиентом события, 0 если не задан
+func sinceParam(c echo.Context) (int64, error) {
+	since := c.QueryParam("since")
+	if since == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(since, 10, 64)
 }
 
 // GET /api/room/:code
@@ -33,7 +105,7 @@ func GetRoom(c echo.Context) error {
 	code := c.Param("code")
 	query := c.QueryParams()
 	playerId := query.Get("playerId")
-	
+
 	room, exists := models.GetRoom(code)
 	if !exists {
 		return c.JSON(http.StatusNotFound, map[string]string{
@@ -48,10 +120,23 @@ func GetRoom(c echo.Context) error {
 		})
 	}
 
-	snapshotChan := room.GetSnapshotForPlayer(playerId)
+	if !room.VerifySession(playerId, sessionToken(c)) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid session token",
+		})
+	}
+
+	since, err := sinceParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid since",
+		})
+	}
+
+	snapshotChan := room.GetSnapshotForPlayer(playerId, since)
 
 	ctx := c.Request().Context()
-	
+
 	select {
 	case snapshot, ok := <-snapshotChan:
 		if !ok {
@@ -59,21 +144,38 @@ func GetRoom(c echo.Context) error {
 				"error": "Failed to get room snapshot",
 			})
 		}
-		
+
 		response := RoomResponse{
-			Code:       snapshot.Code,
-			Players:    snapshot.Players,
-			Started:    snapshot.Started,
-			Characters: snapshot.Characters,
-			Messages:   snapshot.Messages,
+			Code:           snapshot.Code,
+			HostID:         snapshot.HostID,
+			Players:        snapshot.Players,
+			Presence:       snapshot.Presence,
+			Started:        snapshot.Started,
+			Phase:          snapshot.Phase,
+			Settings:       snapshot.Settings,
+			ActivePlayerID: snapshot.ActivePlayerID,
+			TurnRemaining:  snapshot.TurnRemaining,
+			GameRemaining:  snapshot.GameRemaining,
+			Paused:         snapshot.Paused,
+			PausedAt:       snapshot.PausedAt,
+			WrongGuesses:   snapshot.WrongGuesses,
+			Characters:     snapshot.Characters,
+			CharacterInfo:  snapshot.CharacterInfo,
+			Questions:      snapshot.Questions,
+			Round:          snapshot.Round,
+			Scoreboard:     snapshot.Scoreboard,
+			Messages:       snapshot.Events,
+			LastSeq:        snapshot.LastSeq,
+
+			MessagesTruncated: snapshot.EventsTruncated,
 		}
 		return c.JSON(http.StatusOK, response)
-		
+
 	case <-ctx.Done():
 		return c.JSON(http.StatusRequestTimeout, map[string]string{
 			"error": "Request cancelled or timeout",
 		})
-		
+
 	case <-time.After(5 * time.Second):
 		return c.JSON(http.StatusRequestTimeout, map[string]string{
 			"error": "Request timeout",
@@ -81,9 +183,38 @@ func GetRoom(c echo.Context) error {
 	}
 }
 
+type RoomExistsResponse struct {
+	Code  string           `json:"code"`
+	Phase models.GamePhase `json:"phase"`
+}
 
+// GET /api/room/:code/exists
+// Проверка кода комнаты до входа, без сведений об игроках
+func RoomExists(c echo.Context) error {
+	room, exists := models.GetRoom(c.Param("code"))
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Room not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, RoomExistsResponse{
+		Code:  room.Code,
+		Phase: room.CurrentPhase(),
+	})
+}
+
+// JoinRoomRequest в защищённую комнату входят с паролем или приглашением
 type JoinRoomRequest struct {
-	Name string `json:"name"`
+	Name      string `json:"name"`
+	Password  string `json:"password"`
+	Invite    string `json:"invite"`
+	Spectator bool   `json:"spectator"`
+}
+
+type JoinRoomResponse struct {
+	models.Player
+	Token string `json:"token"`
 }
 
 // POST /api/room/:code/join
@@ -110,15 +241,25 @@ func JoinRoom(c echo.Context) error {
 		})
 	}
 
-	player := room.AddPlayer(req.Name)
+	if err := room.AuthorizeJoin(req.Password, req.Invite); err != nil {
+		// Перебор приглашений считается так же, как перебор паролей
+		markFailedAttempt(c)
+		return c.JSON(joinErrorStatus(err), models.NewErrorResponse(err))
+	}
 
-	if player == nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "There is already a user named " + req.Name,
-		})
+	player, err := room.AddPlayer(req.Name, req.Spectator)
+	if err != nil {
+		return c.JSON(joinErrorStatus(err), models.NewErrorResponse(err))
 	}
 
-	return c.JSON(http.StatusOK, player)
+	return c.JSON(http.StatusOK, JoinRoomResponse{
+		Player: *player,
+		Token:  room.SessionToken(player.ID),
+	})
+}
+
+type StartGameRequest struct {
+	PlayerID string `json:"playerId"`
 }
 
 // POST /api/room/:code/start
@@ -132,24 +273,158 @@ func StartGame(c echo.Context) error {
 		})
 	}
 
-	if room.Started {
+	var req StartGameRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request",
+		})
+	}
+
+	if !room.VerifySession(req.PlayerID, sessionToken(c)) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid session token",
+		})
+	}
+
+	if err := room.Authorize(req.PlayerID, models.ActionStartGame); err != nil {
+		return c.JSON(http.StatusForbidden, models.NewErrorResponse(err))
+	}
+
+	if room.CurrentPhase() != models.PhaseLobby {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "Game already started",
 		})
 	}
 
-	if len(room.Players) < 2 {
+	if room.PlayerCount() < 2 {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "Need at least 2 players",
 		})
 	}
 
-	room.StartGame()
+	if err := room.StartGame(); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err))
+	}
 	return c.JSON(http.StatusOK, map[string]string{
 		"status": "Game started",
 	})
 }
 
+type RematchRequest struct {
+	PlayerID string `json:"playerId"`
+	Shuffle  bool   `json:"shuffle"`
+}
+
+// POST /api/room/:code/rematch
+func Rematch(c echo.Context) error {
+	code := c.Param("code")
+	room, exists := models.GetRoom(code)
+
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Room not found",
+		})
+	}
+
+	var req RematchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request",
+		})
+	}
+
+	if !room.VerifySession(req.PlayerID, sessionToken(c)) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid session token",
+		})
+	}
+
+	if err := room.Authorize(req.PlayerID, models.ActionRematch); err != nil {
+		return c.JSON(http.StatusForbidden, models.NewErrorResponse(err))
+	}
+
+	if err := room.Rematch(req.Shuffle); err != nil {
+		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(err))
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "Rematch",
+	})
+}
+
+// joinErrorStatus HTTP-статус для отказа во входе в комнату
+func joinErrorStatus(err error) int {
+	var cmdErr *models.CommandError
+	if errors.As(err, &cmdErr) {
+		switch cmdErr.Code {
+		case models.ErrCodeRoomFull, models.ErrCodeWrongPhase:
+			return http.StatusConflict
+		case models.ErrCodeWrongPassword:
+			return http.StatusUnauthorized
+		case models.ErrCodeForbidden, models.ErrCodeInviteRequired, models.ErrCodeInvalidInvite:
+			return http.StatusForbidden
+		}
+	}
+	return http.StatusBadRequest
+}
+
+type CreateInviteRequest struct {
+	PlayerID string `json:"playerId"`
+	// TTLSec срок действия, 0 значит сутки
+	TTLSec int `json:"ttlSec"`
+}
+
+type CreateInviteResponse struct {
+	Invite    string `json:"invite"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+// POST /api/room/:code/invite
+// Приглашение пускает в комнату без пароля, пока не истечёт или пока
+// не сменится пароль
+func CreateInvite(c echo.Context) error {
+	code := c.Param("code")
+	room, exists := models.GetRoom(code)
+
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Room not found",
+		})
+	}
+
+	var req CreateInviteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request",
+		})
+	}
+
+	if !room.VerifySession(req.PlayerID, sessionToken(c)) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid session token",
+		})
+	}
+
+	if err := room.Authorize(req.PlayerID, models.ActionInvite); err != nil {
+		return c.JSON(http.StatusForbidden, models.NewErrorResponse(err))
+	}
+
+	ttl := time.Duration(req.TTLSec) * time.Second
+	if ttl == 0 {
+		ttl = models.DefaultInviteTTL
+	}
+	if ttl < models.MinInviteTTL || ttl > models.MaxInviteTTL {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "ttlSec must be between 60 and 604800",
+		})
+	}
+
+	invite, expiresAt := room.NewInvite(ttl)
+	return c.JSON(http.StatusCreated, CreateInviteResponse{
+		Invite:    invite,
+		ExpiresAt: expiresAt.Unix(),
+	})
+}
+
 // GET /ping
 func Ping(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sessionToken достаёт токен сессии из заголовка Authorization
+// или из query-параметра token (браузер не умеет заголовки для WebSocket)
+func sessionToken(c echo.Context) string {
+	if auth := c.Request().Header.Get(echo.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return c.QueryParam("token")
+}
+
+var adminToken string
+
+// SetAdminToken задаёт токен администратора, пустой токен отключает доступ
+func SetAdminToken(token string) {
+	adminToken = token
+}
+
+func isAdminToken(token string) bool {
+	return adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
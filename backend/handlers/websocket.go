@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log"
 	"net/http"
 	"tagmyhead/models"
@@ -28,19 +27,30 @@ func WebSocketHandler(c echo.Context) error {
 		})
 	}
 
-	playerExists := false
-	var playerName string
-	for _, p := range room.Players {
-		if p.ID == playerID {
-			playerExists = true
-			playerName = p.Name
-			break
-		}
+	// Проверяем токен до апгрейда, чтобы не открывать сокет чужому.
+	// Пароль и приглашение проверяются при входе, сессию выдают только
+	// после этой проверки. Токен подписан вместе с хэшем пароля, после
+	// смены пароля старые токены сюда не пускают. Токен проверяется
+	// раньше игрока, чтобы не подсказывать, какие ID есть в закрытой
+	// комнате.
+	if !room.VerifySession(playerID, sessionToken(c)) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid session token",
+		})
+	}
+
+	player := room.GetPlayer(playerID)
+	if player == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Invalid session token",
+		})
 	}
+	playerName := player.Name
 
-	if !playerExists {
-		return c.JSON(http.StatusForbidden, map[string]string{
-			"error": "Player not in room",
+	since, err := sinceParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid since",
 		})
 	}
 
@@ -51,128 +61,28 @@ func WebSocketHandler(c echo.Context) error {
 	}
 	defer ws.Close()
 
-	room.AddConnection(playerID, ws)
-	defer room.RemoveConnection(playerID)
-
-	time.Sleep(50 * time.Millisecond)
-
-	gameState := room.GetGameStateForPlayer(playerID)
-	room.SendGameStateToPlayer(playerID, gameState)
-
-	// Отправляем сообщение о присоединении
-	room.Broadcast(models.WSJoinResponse{
-		Type:       "join",
-		PlayerID:   playerID,
-		PlayerName: playerName,
-	})
-
-	// Слушаем сообщения от клиента
-	for {
-		_, msgBytes, err := ws.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
-			}
-			
-			room.Broadcast(models.WSLeaveResponse{
-				Type:       "leave",
-				PlayerID:   playerID,
-				PlayerName: playerName,
-			})
-			break
-		}
-
-		// Сначала читаем только тип сообщения
-		var baseMsg models.WSMessageBase
-		if err := json.Unmarshal(msgBytes, &baseMsg); err != nil {
-			log.Printf("Error parsing message type: %v", err)
-			continue
-		}
-
-		// Обрабатываем в зависимости от типа
-		switch baseMsg.Type {
-		case "ping":
-			room.SendToPlayer(playerID, models.WSPongResponse{
-				Type: "pong",
-			})
-
-		case "set_character":
-			var msg models.WSSetCharacterMessage
-			if err := json.Unmarshal(msgBytes, &msg); err != nil {
-				log.Printf("Error parsing set_character: %v", err)
-				continue
-			}
-			room.SetCharacter(msg)
-
-		case "chat":
-			var msg models.WSChatMessage
-			if err := json.Unmarshal(msgBytes, &msg); err != nil {
-				log.Printf("Error parsing chat: %v", err)
-				continue
-			}
-			msg.PlayerID = playerID
-			msg.PlayerName = playerName
-			room.Broadcast(msg)
-
-		case "guess":
-			var msg models.WSGuessMessage
-			if err := json.Unmarshal(msgBytes, &msg); err != nil {
-				log.Printf("Error parsing guess: %v", err)
-				continue
-			}
-			
-			correctCharacter := room.Characters[playerID]
-			isCorrect := msg.Character == correctCharacter
-			
-			room.Broadcast(models.WSGuessResultResponse{
-				Type:       "guess_result",
-				PlayerID:   playerID,
-				PlayerName: playerName,
-				Character:  msg.Character,
-				Correct:    isCorrect,
-			})
-
-		case "question":
-			var msg models.WSQuestionMessage
-			if err := json.Unmarshal(msgBytes, &msg); err != nil {
-				log.Printf("Error parsing question: %v", err)
-				continue
-			}
-			msg.PlayerID = playerID
-			msg.PlayerName = playerName
-			room.Broadcast(msg)
-
-		case "answer":
-			var msg models.WSQuestionMessage
-			if err := json.Unmarshal(msgBytes, &msg); err != nil {
-				log.Printf("Error parsing answer: %v", err)
-				continue
-			}
-			msg.Type = "answer"
-			msg.PlayerID = playerID
-			msg.PlayerName = playerName
-			room.Broadcast(msg)
-
-		case "move_player":
-			var msg models.WSMovePlayerMessage
-			if err := json.Unmarshal(msgBytes, &msg); err != nil {
-				log.Printf("Error parsing move_player: %v", err)
-				continue
-			}
-			room.MovePlayer(msg.PlayerName, 0)
-
-		case "remove_player":
-			var msg models.WSRemovePlayerMessage
-			if err := json.Unmarshal(msgBytes, &msg); err != nil {
-				log.Printf("Error parsing remove_player: %v", err)
-				continue
-			}
-			room.RemovePlayerWithNotification(msg.RemovedID)
+	// Вместе с init досылаются события, пропущенные с момента since
+	pc, reconnected, err := room.AddConnection(playerID, ws, since)
+	if err != nil {
+		// Игрока удалили, пока открывался сокет
+		log.Printf("WebSocket rejected: %v", err)
+		ws.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "player is not in the room"))
+		return nil
+	}
 
-		default:
-			log.Printf("Unknown message type: %s", baseMsg.Type)
-		}
+	// При переподключении в пределах окна ожидания остальные уже видят
+	// игрока в игре, join не нужен
+	if !reconnected {
+		room.Broadcast(models.WSJoinResponse{
+			Type:       "join",
+			PlayerID:   playerID,
+			PlayerName: playerName,
+			Timestamp:  time.Now().Unix(),
+		})
 	}
 
+	// Все сообщения клиента разбирает models.Room.HandleMessage
+	pc.ReadPump()
 	return nil
 }
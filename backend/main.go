@@ -1,36 +1,140 @@
 package main
 
 import (
+	"expvar"
+	"log"
+	"net/http"
+	"os"
 	"tagmyhead/handlers"
 	"tagmyhead/models"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// openRoomStore открывает и подключает хранилище комнат, затем поднимает
+// комнаты, которые были активны до перезапуска. DB_PATH=memory отключает
+// сохранение на диск.
+func openRoomStore() error {
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = "tagmyhead.db"
+	}
+	if dbPath == "memory" {
+		models.SetRoomStore(models.NewMemoryRoomStore())
+		return nil
+	}
+
+	store, err := models.NewSQLiteRoomStore(dbPath)
+	if err != nil {
+		return err
+	}
+	// Поднятые комнаты сразу запускают таймеры, а те сохраняют комнату,
+	// так что хранилище подключается до загрузки
+	models.SetRoomStore(store)
+
+	restored, err := store.LoadActive(models.RoomTTL)
+	if err != nil {
+		return err
+	}
+	log.Printf("Restored %d rooms from %s", len(restored), dbPath)
+
+	return nil
+}
+
 func main() {
+	// Ключ нужен до загрузки комнат, иначе их сессии не сойдутся
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		models.SetSessionSecret([]byte(secret))
+	} else {
+		log.Printf("SESSION_SECRET is not set, session tokens will not survive a restart")
+	}
+
+	if err := openRoomStore(); err != nil {
+		log.Fatalf("Failed to open room store: %v", err)
+	}
+
+	// Токен администратора для колод без владельца, без него колоды
+	// меняют только их создатели
+	handlers.SetAdminToken(os.Getenv("ADMIN_TOKEN"))
+
+	if grace := os.Getenv("RECONNECT_GRACE"); grace != "" {
+		d, err := time.ParseDuration(grace)
+		if err != nil {
+			log.Fatalf("Invalid RECONNECT_GRACE: %v", err)
+		}
+		models.SetReconnectGracePeriod(d)
+	}
+
+	// Колоды персонажей, каталог по умолчанию необязателен
+	decksDir := os.Getenv("DECKS_DIR")
+	if decksDir == "" {
+		decksDir = "decks"
+	}
+	if err := models.LoadDecks(decksDir); err != nil && (os.Getenv("DECKS_DIR") != "" || !os.IsNotExist(err)) {
+		log.Fatalf("Failed to load decks: %v", err)
+	}
+
+	// Счётчики expvar только на отдельном адресе, например 127.0.0.1:6060,
+	// снаружи они не видны
+	if debugAddr := os.Getenv("DEBUG_ADDR"); debugAddr != "" {
+		go func() {
+			log.Printf("Debug vars on %s", debugAddr)
+			log.Print(http.ListenAndServe(debugAddr, expvar.Handler()))
+		}()
+	}
+
 	// Запуск очистки старых комнат
 	go models.CleanupOldRooms()
 
 	// Echo instance
 	e := echo.New()
 
+	// За прокси адрес клиента берётся из X-Forwarded-For, иначе заголовку
+	// верить нельзя: по IP считаются лимиты попыток. Если сервер стоит за
+	// прокси, а TRUST_PROXY не задан, все клиенты приходят с адреса прокси
+	// и делят одну корзину лимитов: несколько ошибок одного блокируют всех.
+	if os.Getenv("TRUST_PROXY") != "" {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		log.Printf("TRUST_PROXY is not set, attempt limits count by the direct peer address; behind a proxy all clients share one bucket")
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+
+	// Маршруты, через которые можно перебирать коды комнат и пароли
+	limitAttempts := handlers.NewAttemptLimiter(handlers.DefaultAttemptLimiterConfig()).Middleware()
+
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
 
 	e.GET("/ping", handlers.Ping)
-	
-	e.GET("/ws/:code/:playerId", handlers.WebSocketHandler)
-	
+
+	e.GET("/ws/:code/:playerId", handlers.WebSocketHandler, limitAttempts)
+
 	api := e.Group("/api")
 	{
 		room := api.Group("/room")
 		{
 			room.POST("/create", handlers.CreateRoom)
-			room.GET("/:code", handlers.GetRoom)
-			room.POST("/:code/join", handlers.JoinRoom)
+			room.GET("/:code", handlers.GetRoom, limitAttempts)
+			room.GET("/:code/exists", handlers.RoomExists, limitAttempts)
+			room.POST("/:code/join", handlers.JoinRoom, limitAttempts)
 			room.POST("/:code/start", handlers.StartGame)
+			room.POST("/:code/rematch", handlers.Rematch)
+			room.POST("/:code/invite", handlers.CreateInvite)
+		}
+
+		decks := api.Group("/decks")
+		{
+			decks.GET("", handlers.ListDecks)
+			decks.POST("", handlers.CreateDeck)
+			decks.POST("/import", handlers.ImportDeck)
+			decks.GET("/:id", handlers.GetDeck)
+			decks.PUT("/:id", handlers.UpdateDeck)
+			decks.DELETE("/:id", handlers.DeleteDeck)
+			decks.GET("/:id/export", handlers.ExportDeck)
 		}
 	}
 
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Clock источник времени для таймеров комнаты. Подменяется через SetClock,
+// чтобы проверять таймеры без реального ожидания.
+//
+// AfterFunc не должен вызывать f синхронно: таймеры ставятся под dataMu,
+// а их функции берут его заново.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer запущенный таймер Clock
+type Timer interface {
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+var clock Clock = systemClock{}
+
+// SetClock заменяет источник времени, вызывается до создания комнат
+func SetClock(c Clock) {
+	clock = c
+}
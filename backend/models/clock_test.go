@@ -0,0 +1,94 @@
+package models
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock часы для тестов. Таймеры срабатывают только в Advance,
+// который вызывается вне блокировок комнаты.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	f     func()
+	done  bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := !t.done
+	t.done = true
+	return active
+}
+
+// useFakeClock подменяет clock до конца теста
+func useFakeClock(t *testing.T) *fakeClock {
+	t.Helper()
+	c := &fakeClock{now: time.Unix(1700000000, 0)}
+	SetClock(c)
+	t.Cleanup(func() { SetClock(systemClock{}) })
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance сдвигает время на d и по порядку запускает наступившие таймеры,
+// в том числе поставленные другими таймерами по ходу
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		next := c.nextLocked(target)
+		if next == nil {
+			break
+		}
+		next.done = true
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		c.mu.Unlock()
+		next.f()
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+// nextLocked самый ранний таймер, который должен сработать до target
+func (c *fakeClock) nextLocked(target time.Time) *fakeTimer {
+	var next *fakeTimer
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.done {
+			continue
+		}
+		pending = append(pending, timer)
+		if !timer.at.After(target) && (next == nil || timer.at.Before(next.at)) {
+			next = timer
+		}
+	}
+	c.timers = pending
+	return next
+}
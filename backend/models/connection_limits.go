@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+)
+
+const ErrCodeMessageTooLarge = "message_too_large"
+
+// Ограничения входящих сообщений одного соединения
+const (
+	// Сообщения больше maxMessageSize отклоняются с ошибкой,
+	// больше readLimit соединение закрывается сразу
+	maxMessageSize = 4 << 10
+	readLimit      = 64 << 10
+
+	messageRate  = 10
+	messageBurst = 20
+
+	// maxViolations нарушений подряд, не дальше violationWindow друг от
+	// друга, и соединение закрывается
+	maxViolations   = 5
+	violationWindow = 30 * time.Second
+
+	// closeGracePeriod сколько ждать ответного close от клиента
+	closeGracePeriod = 5 * time.Second
+)
+
+// messageCooldowns минимальный интервал между сообщениями одного типа.
+// Сам чат хранится в журнале событий и ограничен MaxEvents.
+var messageCooldowns = map[string]time.Duration{
+	"guess": time.Second,
+	"chat":  500 * time.Millisecond,
+}
+
+// connectionLimiter лимиты одного соединения. Используется только из
+// ReadPump, поэтому без блокировок.
+type connectionLimiter struct {
+	messages      *rate.Limiter
+	lastByType    map[string]time.Time
+	violations    int
+	lastViolation time.Time
+}
+
+func newConnectionLimiter() *connectionLimiter {
+	return &connectionLimiter{
+		messages:   rate.NewLimiter(messageRate, messageBurst),
+		lastByType: make(map[string]time.Time),
+	}
+}
+
+// check пропускает сообщение или объясняет, какой лимит превышен
+func (l *connectionLimiter) check(data []byte) error {
+	if len(data) > maxMessageSize {
+		return &CommandError{
+			Code:    ErrCodeMessageTooLarge,
+			Message: fmt.Sprintf("message is larger than %d bytes", maxMessageSize),
+		}
+	}
+
+	now := time.Now()
+	if !l.messages.AllowN(now, 1) {
+		return &CommandError{
+			Code:    ErrCodeRateLimited,
+			Message: "too many messages, slow down",
+		}
+	}
+
+	// Неразобранный тип отклонит HandleMessage
+	var base WSMessageBase
+	if json.Unmarshal(data, &base) != nil {
+		return nil
+	}
+	if cooldown, exists := messageCooldowns[base.Type]; exists {
+		if wait := l.lastByType[base.Type].Add(cooldown).Sub(now); wait > 0 {
+			return &CommandError{
+				Code:    ErrCodeRateLimited,
+				Message: fmt.Sprintf("wait %d ms before the next %s", wait.Milliseconds(), base.Type),
+			}
+		}
+		l.lastByType[base.Type] = now
+	}
+	return nil
+}
+
+// violation учитывает нарушение, true если пора закрыть соединение
+func (l *connectionLimiter) violation() bool {
+	now := time.Now()
+	if now.Sub(l.lastViolation) > violationWindow {
+		l.violations = 0
+	}
+	l.violations++
+	l.lastViolation = now
+	return l.violations >= maxViolations
+}
+
+// closeFrame просит writePump закрыть соединение после уже
+// поставленных в очередь сообщений
+type closeFrame struct {
+	code   int
+	reason string
+}
+
+func (f closeFrame) message() []byte {
+	return websocket.FormatCloseMessage(f.code, f.reason)
+}
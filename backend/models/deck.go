@@ -0,0 +1,308 @@
+package models
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DeckEntry карточка колоды: персонаж и его другие имена
+type DeckEntry struct {
+	Name       string   `json:"name" yaml:"name"`
+	Aliases    []string `json:"aliases,omitempty" yaml:"aliases"`
+	Category   string   `json:"category,omitempty" yaml:"category"`
+	Difficulty string   `json:"difficulty,omitempty" yaml:"difficulty"`
+	Language   string   `json:"language,omitempty" yaml:"language"`
+}
+
+// Deck набор персонажей, из которого сервер раздаёт и подсказывает карточки
+type Deck struct {
+	ID      string      `json:"id" yaml:"id"`
+	Name    string      `json:"name" yaml:"name"`
+	Entries []DeckEntry `json:"entries" yaml:"entries"`
+
+	// ReadOnly колода загружена из DECKS_DIR и через API не меняется
+	ReadOnly bool `json:"readOnly,omitempty" yaml:"-"`
+
+	// Owner ID игрока, создавшего колоду через API. Колоды без владельца
+	// меняет только администратор.
+	Owner string `json:"owner,omitempty" yaml:"-"`
+}
+
+// DeckFilter отбор карточек колоды, пустые поля не ограничивают.
+// Карточки без языка подходят под любой язык.
+type DeckFilter struct {
+	Category   string `json:"category,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	Language   string `json:"language,omitempty"`
+}
+
+func (f DeckFilter) matches(entry DeckEntry) bool {
+	return (f.Category == "" || strings.EqualFold(f.Category, entry.Category)) &&
+		(f.Difficulty == "" || strings.EqualFold(f.Difficulty, entry.Difficulty)) &&
+		(f.Language == "" || entry.Language == "" || strings.EqualFold(f.Language, entry.Language))
+}
+
+// Filter карточки, подходящие под фильтр
+func (d *Deck) Filter(filter DeckFilter) []DeckEntry {
+	entries := make([]DeckEntry, 0, len(d.Entries))
+	for _, entry := range d.Entries {
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Sample до n разных случайных карточек, подходящих под фильтр
+func (d *Deck) Sample(n int, filter DeckFilter) []DeckEntry {
+	entries := d.Filter(filter)
+	rand.Shuffle(len(entries), func(i, j int) {
+		entries[i], entries[j] = entries[j], entries[i]
+	})
+	if n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// Колоды из файлов живут только в памяти, их источник каталог DECKS_DIR
+var (
+	fileDecks   = make(map[string]*Deck)
+	fileDecksMu sync.RWMutex
+)
+
+// LoadDecks загружает колоды из .json, .csv, .yaml и .yml файлов каталога.
+// ID колоды имя файла без расширения. Файлы с ошибками пропускаются.
+func LoadDecks(dir string) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]*Deck)
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		deck, err := loadDeckFile(path)
+		if err != nil {
+			log.Printf("Error loading deck %s: %v", path, err)
+			continue
+		}
+		if deck != nil {
+			deck.ReadOnly = true
+			loaded[deck.ID] = deck
+		}
+	}
+
+	fileDecksMu.Lock()
+	for id, deck := range loaded {
+		fileDecks[id] = deck
+	}
+	fileDecksMu.Unlock()
+
+	log.Printf("Loaded %d character decks from %s", len(loaded), dir)
+	return nil
+}
+
+// loadDeckFile читает колоду, nil без ошибки для файлов другого формата
+func loadDeckFile(path string) (*Deck, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	if _, known := deckParsers[ext]; !known {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	deck, err := ParseDeck(ext, f)
+	if err != nil {
+		return nil, err
+	}
+	if deck.ID == "" {
+		deck.ID = id
+	}
+	return deck, deck.validate()
+}
+
+// MaxDeckEntries предел размера одной колоды
+const MaxDeckEntries = 5000
+
+var deckParsers = map[string]func(io.Reader) (*Deck, error){
+	".json": parseDeckJSON,
+	".csv":  parseDeckCSV,
+	".yaml": parseDeckYAML,
+	".yml":  parseDeckYAML,
+}
+
+// ParseDeck разбирает колоду в формате json, csv или yaml, точка перед
+// форматом необязательна. Колоду нужно проверить через SaveDeck.
+func ParseDeck(format string, r io.Reader) (*Deck, error) {
+	format = strings.ToLower(format)
+	if !strings.HasPrefix(format, ".") {
+		format = "." + format
+	}
+	parse, exists := deckParsers[format]
+	if !exists {
+		return nil, fmt.Errorf("unknown deck format %q", strings.TrimPrefix(format, "."))
+	}
+	return parse(r)
+}
+
+func (d *Deck) validate() error {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" {
+		d.Name = d.ID
+	}
+
+	entries := d.Entries[:0]
+	for _, entry := range d.Entries {
+		entry.Name = strings.TrimSpace(entry.Name)
+		if entry.Name == "" {
+			continue
+		}
+		entry.Aliases = cleanAliases(entry.Name, entry.Aliases)
+		entry.Category = strings.TrimSpace(entry.Category)
+		entry.Difficulty = strings.TrimSpace(entry.Difficulty)
+		entry.Language = strings.ToLower(strings.TrimSpace(entry.Language))
+		entries = append(entries, entry)
+	}
+	d.Entries = entries
+
+	if len(d.Entries) == 0 {
+		return fmt.Errorf("deck %s has no entries", d.ID)
+	}
+	if len(d.Entries) > MaxDeckEntries {
+		return fmt.Errorf("deck %s has more than %d entries", d.ID, MaxDeckEntries)
+	}
+	return nil
+}
+
+// parseDeckJSON принимает объект колоды или просто массив карточек
+func parseDeckJSON(r io.Reader) (*Deck, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var deck Deck
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &deck.Entries)
+	} else {
+		err = json.Unmarshal(data, &deck)
+	}
+	return &deck, err
+}
+
+// parseDeckYAML принимает объект колоды или просто список карточек
+func parseDeckYAML(r io.Reader) (*Deck, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	var deck Deck
+	if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+		err = node.Decode(&deck.Entries)
+	} else {
+		err = node.Decode(&deck)
+	}
+	return &deck, err
+}
+
+// Столбцы CSV колоды в порядке экспорта
+var deckCSVColumns = []string{"name", "aliases", "category", "difficulty", "language"}
+
+// parseDeckCSV ожидает заголовок со столбцами name, aliases, category,
+// difficulty, language в любом порядке. Имена в aliases разделяются символом |.
+func parseDeckCSV(r io.Reader) (*Deck, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, exists := columns["name"]; !exists {
+		return nil, fmt.Errorf("csv deck has no name column")
+	}
+
+	field := func(record []string, column string) string {
+		i, exists := columns[column]
+		if !exists || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	deck := &Deck{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := DeckEntry{
+			Name:       field(record, "name"),
+			Category:   field(record, "category"),
+			Difficulty: field(record, "difficulty"),
+			Language:   field(record, "language"),
+		}
+		if aliases := field(record, "aliases"); aliases != "" {
+			entry.Aliases = strings.Split(aliases, "|")
+		}
+		deck.Entries = append(deck.Entries, entry)
+	}
+	return deck, nil
+}
+
+// WriteCSV выгружает карточки в формате, который понимает parseDeckCSV
+func (d *Deck) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(deckCSVColumns); err != nil {
+		return err
+	}
+	for _, entry := range d.Entries {
+		record := []string{
+			entry.Name,
+			strings.Join(entry.Aliases, "|"),
+			entry.Category,
+			entry.Difficulty,
+			entry.Language,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/hex"
+	"sort"
+)
+
+const ErrCodeDeckInUse = "deck_in_use"
+
+// DeckStore хранилище колод, созданных через API. Реализуется теми же
+// хранилищами, что и комнаты, SetRoomStore подхватывает его автоматически.
+type DeckStore interface {
+	GetDeck(id string) (*Deck, bool)
+	// SaveDeck добавляет колоду или заменяет колоду с тем же ID
+	SaveDeck(deck *Deck) error
+	// DeleteDeck удаляет колоду, false если её не было
+	DeleteDeck(id string) (bool, error)
+	Decks() []*Deck
+}
+
+var deckStore = defaultDeckStore()
+
+// defaultDeckStore хранилище комнат, если оно умеет хранить колоды,
+// иначе отдельное хранилище в памяти
+func defaultDeckStore() DeckStore {
+	if decks, ok := store.(DeckStore); ok {
+		return decks
+	}
+	return NewMemoryRoomStore()
+}
+
+// GetDeck ищет колоду среди загруженных из файлов и сохранённых через API
+func GetDeck(id string) (*Deck, bool) {
+	fileDecksMu.RLock()
+	deck, exists := fileDecks[id]
+	fileDecksMu.RUnlock()
+	if exists {
+		return deck, true
+	}
+	return deckStore.GetDeck(id)
+}
+
+// Decks все колоды по ID
+func Decks() []*Deck {
+	list := deckStore.Decks()
+
+	fileDecksMu.RLock()
+	for _, deck := range fileDecks {
+		list = append(list, deck)
+	}
+	fileDecksMu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// SaveDeck проверяет колоду и сохраняет её в хранилище. Колоды из файлов
+// не перезаписываются. Сохранённая колода дальше не меняется на месте,
+// комнаты читают её без блокировок.
+func SaveDeck(deck *Deck) error {
+	if existing, exists := GetDeck(deck.ID); exists && existing.ReadOnly {
+		return &CommandError{Code: ErrCodeForbidden, Message: "deck " + deck.ID + " is read-only"}
+	}
+	deck.ReadOnly = false
+	if err := deck.validate(); err != nil {
+		return badRequest("%v", err)
+	}
+	return deckStore.SaveDeck(deck)
+}
+
+// DeleteDeck удаляет колоду из хранилища, false если её не было.
+// Колоду, выбранную в одной из комнат, удалить нельзя.
+func DeleteDeck(id string) (bool, error) {
+	if deck, exists := GetDeck(id); exists && deck.ReadOnly {
+		return false, &CommandError{Code: ErrCodeForbidden, Message: "deck " + id + " is read-only"}
+	}
+	if code, used := deckInUse(id); used {
+		return false, &CommandError{Code: ErrCodeDeckInUse, Message: "deck " + id + " is used by room " + code}
+	}
+	return deckStore.DeleteDeck(id)
+}
+
+// deckInUse код комнаты, в настройках которой выбрана колода
+func deckInUse(id string) (string, bool) {
+	for _, room := range store.Rooms() {
+		room.dataMu.RLock()
+		used := room.Settings.Deck == id
+		room.dataMu.RUnlock()
+		if used {
+			return room.Code, true
+		}
+	}
+	return "", false
+}
+
+func NewDeckID() string {
+	return hex.EncodeToString(mustRandomBytes(6))
+}
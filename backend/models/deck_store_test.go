@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// useMemoryStore подменяет хранилище комнат и колод до конца теста
+func useMemoryStore(t *testing.T) {
+	t.Helper()
+	prevStore, prevDecks := store, deckStore
+	SetRoomStore(NewMemoryRoomStore())
+	t.Cleanup(func() {
+		for _, room := range store.Rooms() {
+			room.Close()
+		}
+		store, deckStore = prevStore, prevDecks
+	})
+}
+
+func TestDeleteDeckInUse(t *testing.T) {
+	useMemoryStore(t)
+
+	deck := &Deck{ID: NewDeckID(), Entries: []DeckEntry{{Name: "Harry Potter"}}}
+	if err := SaveDeck(deck); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := DefaultRoomSettings()
+	settings.Deck = deck.ID
+	room, err := CreateRoom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cmdErr *CommandError
+	if _, err := DeleteDeck(deck.ID); !errors.As(err, &cmdErr) || cmdErr.Code != ErrCodeDeckInUse {
+		t.Fatalf("DeleteDeck() error = %v, want %s", err, ErrCodeDeckInUse)
+	}
+
+	if _, err := store.Delete(room.Code); err != nil {
+		t.Fatal(err)
+	}
+	room.Close()
+	deleted, err := DeleteDeck(deck.ID)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteDeck() = %v, %v after the room is gone", deleted, err)
+	}
+}
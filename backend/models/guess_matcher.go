@@ -0,0 +1,208 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+type MatchResult string
+
+const (
+	// MatchExact совпадение с точностью до регистра, диакритики и пунктуации
+	MatchExact MatchResult = "exact"
+	// MatchFuzzy засчитано после транслитерации или с небольшими опечатками
+	MatchFuzzy MatchResult = "fuzzy"
+	// MatchNearMiss не засчитано, но догадка близка к ответу
+	MatchNearMiss MatchResult = "near_miss"
+	MatchMiss     MatchResult = "miss"
+)
+
+// Accepted засчитывается ли догадка
+func (m MatchResult) Accepted() bool {
+	return m == MatchExact || m == MatchFuzzy
+}
+
+// GuessMatcher сравнивает догадку со всеми допустимыми ответами
+type GuessMatcher interface {
+	Match(guess string, answers []string) MatchResult
+}
+
+// FuzzyMatcher нормализует строки, переводит кириллицу в латиницу и
+// сравнивает по расстоянию Левенштейна относительно длины ответа
+type FuzzyMatcher struct {
+	// AcceptRatio доля опечаток, при которой догадка ещё засчитывается
+	AcceptRatio float64
+	// NearMissRatio доля опечаток, при которой догадка считается близкой
+	NearMissRatio float64
+	// MinFuzzyLength ответы короче засчитываются только точно
+	MinFuzzyLength int
+}
+
+func NewFuzzyMatcher() *FuzzyMatcher {
+	return &FuzzyMatcher{
+		AcceptRatio:    0.2,
+		NearMissRatio:  0.4,
+		MinFuzzyLength: 5,
+	}
+}
+
+var guessMatcher GuessMatcher = NewFuzzyMatcher()
+
+// SetGuessMatcher заменяет правила сравнения догадок для обычной строгости
+func SetGuessMatcher(m GuessMatcher) {
+	guessMatcher = m
+}
+
+// MatchStrictness строгость сравнения догадок, задаётся в настройках комнаты
+type MatchStrictness string
+
+const (
+	// MatchStrict засчитываются только точные совпадения и транслитерация
+	MatchStrict  MatchStrictness = "strict"
+	MatchNormal  MatchStrictness = "normal"
+	MatchLenient MatchStrictness = "lenient"
+)
+
+func (s MatchStrictness) Valid() bool {
+	switch s {
+	case MatchStrict, MatchNormal, MatchLenient:
+		return true
+	}
+	return false
+}
+
+var strictnessMatchers = map[MatchStrictness]GuessMatcher{
+	MatchStrict: &FuzzyMatcher{
+		AcceptRatio:    0,
+		NearMissRatio:  0.4,
+		MinFuzzyLength: 5,
+	},
+	MatchLenient: &FuzzyMatcher{
+		AcceptRatio:    0.3,
+		NearMissRatio:  0.5,
+		MinFuzzyLength: 4,
+	},
+}
+
+// matcherFor правила сравнения для строгости комнаты
+func matcherFor(strictness MatchStrictness) GuessMatcher {
+	if m, exists := strictnessMatchers[strictness]; exists {
+		return m
+	}
+	return guessMatcher
+}
+
+func (m *FuzzyMatcher) Match(guess string, answers []string) MatchResult {
+	best := MatchMiss
+	normalizedGuess := normalizeGuess(guess)
+	latinGuess := transliterate(normalizedGuess)
+
+	for _, answer := range answers {
+		normalized := normalizeGuess(answer)
+		if normalized == "" {
+			continue
+		}
+		if normalized == normalizedGuess {
+			return MatchExact
+		}
+
+		latin := transliterate(normalized)
+		if latin == latinGuess {
+			best = MatchFuzzy
+			continue
+		}
+
+		length := len([]rune(latin))
+		distance := float64(levenshtein(latinGuess, latin))
+		if length < m.MinFuzzyLength {
+			// В коротких именах одна буква меняет смысл, только подсказываем
+			if distance <= 1 && best == MatchMiss {
+				best = MatchNearMiss
+			}
+			continue
+		}
+		switch {
+		case distance <= m.AcceptRatio*float64(length):
+			best = MatchFuzzy
+		case distance <= m.NearMissRatio*float64(length) && best == MatchMiss:
+			best = MatchNearMiss
+		}
+	}
+	return best
+}
+
+// Буквы, в которых знак над буквой часть самой буквы, а не диакритика:
+// без него «й» стала бы «и», и транслитерация дала бы не то
+var keepMarks = runes.Predicate(func(r rune) bool {
+	switch r {
+	case 'й', 'Й', 'ё', 'Ё', 'ї', 'Ї':
+		return true
+	}
+	return false
+})
+
+// normalizeGuess убирает диакритику, регистр и пунктуацию,
+// слова разделяются одним пробелом
+func normalizeGuess(s string) string {
+	strip := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	t := transform.Chain(norm.NFC, runes.If(keepMarks, nil, strip))
+	decomposed, _, err := transform.String(t, s)
+	if err != nil {
+		decomposed = s
+	}
+	folded := cases.Fold().String(decomposed)
+
+	fields := strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Упрощённая транслитерация, близкая к тому, как имена пишут латиницей
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f",
+	'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y",
+	'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "i", 'є': "e",
+	'ё': "e",
+}
+
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,164 @@
+package models
+
+import "testing"
+
+func TestNormalizeGuess(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Harry Potter", "harry potter"},
+		{"  Hárry   Pötter!! ", "harry potter"},
+		{"Гарри Поттер", "гарри поттер"},
+		{"Ёжик в тумане", "ёжик в тумане"},
+		// Краткая и две точки часть буквы, а не диакритика
+		{"Йода", "йода"},
+		{"Андрей", "андрей"},
+		{"Її", "її"},
+		{"Zoë Saldaña", "zoe saldana"},
+		{"R2-D2", "r2 d2"},
+		{"Straße", "strasse"},
+		{"...", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeGuess(tt.in); got != tt.want {
+			t.Errorf("normalizeGuess(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"гарри поттер", "garri potter"},
+		{"чебурашка", "cheburashka"},
+		{"щука", "schuka"},
+		{"объект", "obekt"},
+		{"юля", "yulya"},
+		{"йода", "yoda"},
+		{"андрей", "andrey"},
+		{"ёжик", "ezhik"},
+		{"harry potter", "harry potter"},
+		{"r2 d2", "r2 d2"},
+	}
+	for _, tt := range tests {
+		if got := transliterate(tt.in); got != tt.want {
+			t.Errorf("transliterate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"harry potter", "harry poter", 1},
+		// Считаются руны, а не байты
+		{"гарри", "гари", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyMatcher(t *testing.T) {
+	harry := []string{"Harry Potter", "The Boy Who Lived"}
+
+	tests := []struct {
+		name    string
+		guess   string
+		answers []string
+		want    MatchResult
+	}{
+		{"exact", "Harry Potter", harry, MatchExact},
+		{"case and punctuation", "harry potter!", harry, MatchExact},
+		{"alias", "the boy who lived", harry, MatchExact},
+		{"cyrillic guess", "гарри поттер", harry, MatchFuzzy},
+		{"typo", "Harry Poter", harry, MatchFuzzy},
+		{"latin guess for cyrillic answer", "Cheburashka", []string{"Чебурашка"}, MatchFuzzy},
+		{"short i", "Yoda", []string{"Йода"}, MatchFuzzy},
+		{"short i in the guess", "Йода", []string{"Yoda"}, MatchFuzzy},
+		{"short i at the end", "Andrey", []string{"Андрей"}, MatchFuzzy},
+		{"yo written as e", "Ежик в тумане", []string{"Ёжик в тумане"}, MatchFuzzy},
+		{"other character", "Gandalf", harry, MatchMiss},
+		{"empty guess", "", harry, MatchMiss},
+		{"empty answers", "Harry", []string{"", "!!"}, MatchMiss},
+
+		// Ответ из 10 букв: засчитывается до 2 опечаток, близко до 4
+		{"2 typos accepted", "Dumblexxre", []string{"Dumbledore"}, MatchFuzzy},
+		{"3 typos near miss", "Dumblxxxre", []string{"Dumbledore"}, MatchNearMiss},
+		{"4 typos near miss", "Dumbxxxxre", []string{"Dumbledore"}, MatchNearMiss},
+		{"5 typos miss", "Dumxxxxxre", []string{"Dumbledore"}, MatchMiss},
+
+		// Короткие ответы засчитываются только точно
+		{"short exact", "Neo", []string{"Neo"}, MatchExact},
+		{"short typo", "Nemo", []string{"Neo"}, MatchNearMiss},
+		{"short other", "Max", []string{"Neo"}, MatchMiss},
+	}
+
+	matcher := NewFuzzyMatcher()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.Match(tt.guess, tt.answers); got != tt.want {
+				t.Errorf("Match(%q, %q) = %s, want %s", tt.guess, tt.answers, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchStrictness(t *testing.T) {
+	dumbledore := []string{"Dumbledore"}
+
+	tests := []struct {
+		strictness MatchStrictness
+		guess      string
+		answers    []string
+		want       MatchResult
+	}{
+		{MatchStrict, "Dumbledore", dumbledore, MatchExact},
+		{MatchStrict, "Cheburashka", []string{"Чебурашка"}, MatchFuzzy},
+		{MatchStrict, "Dumbledxre", dumbledore, MatchNearMiss},
+		{MatchStrict, "Dumbxxxxre", dumbledore, MatchNearMiss},
+		{MatchStrict, "Dumxxxxxre", dumbledore, MatchMiss},
+
+		{MatchNormal, "Dumblexxre", dumbledore, MatchFuzzy},
+		{MatchNormal, "Dumblxxxre", dumbledore, MatchNearMiss},
+		{MatchNormal, "Yodo", []string{"Yoda"}, MatchNearMiss},
+
+		{MatchLenient, "Dumblxxxre", dumbledore, MatchFuzzy},
+		{MatchLenient, "Dumxxxxxre", dumbledore, MatchNearMiss},
+		{MatchLenient, "Duxxxxxxre", dumbledore, MatchMiss},
+		{MatchLenient, "Yodo", []string{"Yoda"}, MatchFuzzy},
+		{MatchLenient, "Neo", []string{"Nea"}, MatchNearMiss},
+
+		// Неизвестная строгость работает как обычная
+		{"", "Dumblexxre", dumbledore, MatchFuzzy},
+	}
+	for _, tt := range tests {
+		if got := matcherFor(tt.strictness).Match(tt.guess, tt.answers); got != tt.want {
+			t.Errorf("%q: Match(%q, %q) = %s, want %s", tt.strictness, tt.guess, tt.answers, got, tt.want)
+		}
+	}
+}
+
+func TestMatchResultAccepted(t *testing.T) {
+	for result, want := range map[MatchResult]bool{
+		MatchExact:    true,
+		MatchFuzzy:    true,
+		MatchNearMiss: false,
+		MatchMiss:     false,
+	} {
+		if got := result.Accepted(); got != want {
+			t.Errorf("%s.Accepted() = %v, want %v", result, got, want)
+		}
+	}
+}
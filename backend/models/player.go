@@ -1,7 +1,15 @@
 package models
 
 type Player struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	IsWinner bool   `json:"isWinner"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	IsWinner     bool   `json:"isWinner"`
+	IsEliminated bool   `json:"isEliminated"`
+	IsSpectator  bool   `json:"isSpectator"`
+	Score        int    `json:"score"` // очки за все раунды в комнате
+}
+
+// inGame игрок ещё угадывает: не зритель, не отгадал и не выбыл
+func (p Player) inGame() bool {
+	return !p.IsSpectator && !p.IsWinner && !p.IsEliminated
 }
@@ -7,17 +7,66 @@ import (
 
 type Room struct {
 	Code       string            `json:"code"`
+	HostID     string            `json:"hostId"`
 	Players    []Player          `json:"players"`
 	Started    bool              `json:"started"`
+	Phase      GamePhase         `json:"phase"`
 	Characters map[string]string `json:"characters"`
 	WhoMakeFor map[string]Player `json:"who_make_for"`
 	CreatedAt  time.Time         `json:"created_at"`
-	Messages   []interface{}     `json:"messages"`
+
+	// CharacterInfo альтернативные имена и описания персонажей
+	CharacterInfo map[string]CharacterInfo `json:"characterInfo"`
+
+	Settings     RoomSettings `json:"settings"`
+	PasswordHash string       `json:"-"`
+
+	// SessionNonces входят в подпись токенов сессий, смена nonce
+	// отзывает все токены игрока
+	SessionNonces map[string]string `json:"-"`
+
+	ActivePlayerID string    `json:"activePlayerId"`
+	TurnDeadline   time.Time `json:"turnDeadline"`
+	GameDeadline   time.Time `json:"gameDeadline"`
+
+	Paused   bool      `json:"paused"`
+	PausedAt time.Time `json:"pausedAt"`
+
+	GuessCooldowns map[string]time.Time `json:"guessCooldowns"`
+
+	Questions []Question `json:"questions"`
+
+	Winners           []WinRecord    `json:"winners"`
+	GuessCounts       map[string]int `json:"guessCounts"`
+	WrongGuesses      map[string]int `json:"wrongGuesses"`
+	GuessingStartedAt time.Time      `json:"guessingStartedAt"`
+	FinishedAt        time.Time      `json:"finishedAt"`
+
+	Round      int                   `json:"round"`
+	Scoreboard map[string]ScoreEntry `json:"scoreboard"`
+
+	// RoundPlayers сколько игроков было в начале раунда, по нему
+	// checkGameOver решает, когда игра закончена
+	RoundPlayers int `json:"roundPlayers"`
+
+	Events  []Event `json:"events"`
+	LastSeq int64   `json:"lastSeq"`
 
 	Connections map[string]*PlayerConnection `json:"-"`
+	Presence    map[string]PresenceState     `json:"-"`
+	graceTimers map[string]Timer
+	voteTimers  map[string]Timer
+	turnTimer   Timer
+	gameTimer   Timer
+	tickTimer   Timer
 	connMu      sync.RWMutex
 	dataMu      sync.RWMutex
-	
+	eventMu     sync.Mutex
+
+	// Отложенное сохранение, см. persist
+	saveMu        sync.Mutex
+	saveScheduled bool
+
 	// Канал для запросов снимков комнаты
 	snapshotRequests chan snapshotRequest
 }
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	ErrCodeWrongPassword  = "wrong_password"
+	ErrCodeInviteRequired = "invite_required"
+	ErrCodeInvalidInvite  = "invalid_invite"
+	ErrCodeRateLimited    = "rate_limited"
+)
+
+// Срок действия приглашений
+const (
+	DefaultInviteTTL = 24 * time.Hour
+	MinInviteTTL     = time.Minute
+	MaxInviteTTL     = 7 * 24 * time.Hour
+)
+
+// takePassword забирает новый пароль из настроек и возвращает его хэш.
+// changed=false, если пароль не передавался. Пустой хэш снимает пароль.
+func (s *RoomSettings) takePassword() (hash string, changed bool, err error) {
+	if s.Password == nil {
+		return "", false, nil
+	}
+	password := *s.Password
+	s.Password = nil
+	if password == "" {
+		return "", true, nil
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", false, err
+	}
+	return string(hashed), true, nil
+}
+
+// CheckPassword подходит ли пароль для входа, без пароля в комнату пускают всех
+func (r *Room) CheckPassword(password string) bool {
+	r.dataMu.RLock()
+	hash := r.PasswordHash
+	r.dataMu.RUnlock()
+
+	if hash == "" {
+		return true
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// inviteSignature подпись приглашения. В неё входит хэш пароля, поэтому
+// смена пароля отзывает все выданные приглашения.
+func (r *Room) inviteSignature(expires string) []byte {
+	r.dataMu.RLock()
+	hash := r.PasswordHash
+	r.dataMu.RUnlock()
+
+	mac := hmac.New(sha256.New, sessionSecret)
+	mac.Write([]byte("invite"))
+	mac.Write([]byte{0})
+	mac.Write([]byte(r.Code))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expires))
+	mac.Write([]byte{0})
+	mac.Write([]byte(hash))
+	return mac.Sum(nil)
+}
+
+// InviteToken подписанное приглашение в комнату, действует до expiresAt
+func (r *Room) InviteToken(expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 36)
+	return expires + "." + base64.RawURLEncoding.EncodeToString(r.inviteSignature(expires))
+}
+
+// NewInvite приглашение на ttl от текущего времени. Срок считается по тем
+// же часам, что и в VerifyInvite.
+func (r *Room) NewInvite(ttl time.Duration) (token string, expiresAt time.Time) {
+	expiresAt = clock.Now().Add(ttl)
+	return r.InviteToken(expiresAt), expiresAt
+}
+
+// VerifyInvite проверяет подпись и срок действия приглашения
+func (r *Room) VerifyInvite(token string) bool {
+	expires, signature, found := strings.Cut(token, ".")
+	if !found {
+		return false
+	}
+	unix, err := strconv.ParseInt(expires, 36, 64)
+	if err != nil || clock.Now().Unix() >= unix {
+		return false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, r.inviteSignature(expires))
+}
+
+// AuthorizeJoin пускает нового игрока по приглашению или паролю.
+// В комнату только по приглашениям пароль не пускает.
+func (r *Room) AuthorizeJoin(password, invite string) error {
+	if invite != "" {
+		if !r.VerifyInvite(invite) {
+			return &CommandError{Code: ErrCodeInvalidInvite, Message: "invite is invalid or expired"}
+		}
+		return nil
+	}
+
+	r.dataMu.RLock()
+	inviteOnly := r.Settings.InviteOnly
+	r.dataMu.RUnlock()
+	if inviteOnly {
+		return &CommandError{Code: ErrCodeInviteRequired, Message: "this room is invite-only"}
+	}
+
+	if !r.CheckPassword(password) {
+		return &CommandError{Code: ErrCodeWrongPassword, Message: "wrong room password"}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// setRoomSettings применяет patch как команда update_settings
+func setRoomSettings(t *testing.T, room *Room, patch string) RoomSettings {
+	t.Helper()
+	settings, err := room.UpdateSettings(json.RawMessage(patch))
+	if err != nil {
+		t.Fatalf("UpdateSettings(%s): %v", patch, err)
+	}
+	return settings
+}
+
+func errorCode(err error) string {
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code
+	}
+	return ""
+}
+
+func TestAuthorizeJoin(t *testing.T) {
+	c := useFakeClock(t)
+
+	open := newTestRoom(t, "alice")
+	locked := newTestRoom(t, "alice")
+	setRoomSettings(t, locked, `{"password": "secret"}`)
+	inviteOnly := newTestRoom(t, "alice")
+	setRoomSettings(t, inviteOnly, `{"password": "secret", "inviteOnly": true}`)
+
+	expiresAt := c.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		room     *Room
+		password string
+		invite   string
+		want     string
+	}{
+		{"open room", open, "", "", ""},
+		{"open room with any password", open, "whatever", "", ""},
+		{"right password", locked, "secret", "", ""},
+		{"wrong password", locked, "guess", "", ErrCodeWrongPassword},
+		{"no password", locked, "", "", ErrCodeWrongPassword},
+		{"invite instead of password", locked, "", locked.InviteToken(expiresAt), ""},
+		{"invite from another room", locked, "secret", inviteOnly.InviteToken(expiresAt), ErrCodeInvalidInvite},
+		{"garbage invite", locked, "", "not-an-invite", ErrCodeInvalidInvite},
+		{"invite-only without invite", inviteOnly, "secret", "", ErrCodeInviteRequired},
+		{"invite-only with invite", inviteOnly, "", inviteOnly.InviteToken(expiresAt), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCode(tt.room.AuthorizeJoin(tt.password, tt.invite)); got != tt.want {
+				t.Errorf("AuthorizeJoin() error code = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInviteExpiresAndIsRevokedByPasswordChange(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice")
+	setRoomSettings(t, room, `{"password": "secret"}`)
+
+	short, _ := room.NewInvite(time.Minute)
+	long, _ := room.NewInvite(time.Hour)
+
+	c.Advance(time.Minute)
+	if room.VerifyInvite(short) {
+		t.Error("expired invite is accepted")
+	}
+	if !room.VerifyInvite(long) {
+		t.Fatal("valid invite is rejected")
+	}
+
+	setRoomSettings(t, room, `{"password": "changed"}`)
+	if room.VerifyInvite(long) {
+		t.Error("invite survived a password change")
+	}
+}
+
+func TestHasPasswordIsDerived(t *testing.T) {
+	room := newTestRoom(t, "alice")
+
+	if settings := setRoomSettings(t, room, `{"hasPassword": true}`); settings.HasPassword {
+		t.Error("client set hasPassword without a password")
+	}
+	if settings := setRoomSettings(t, room, `{"password": "secret", "hasPassword": false}`); !settings.HasPassword {
+		t.Error("hasPassword is false for a room with a password")
+	}
+	if settings := setRoomSettings(t, room, `{"password": ""}`); settings.HasPassword {
+		t.Error("hasPassword is true after the password was removed")
+	}
+
+	// Клиенты получают тот же флаг в состоянии комнаты
+	setRoomSettings(t, room, `{"password": "secret"}`)
+	if state := room.GetGameStateForPlayer(playerID(t, room, "alice")); !state.Settings.HasPassword {
+		t.Error("game state hides the password flag")
+	}
+}
+
+func TestPasswordChangeRenewsSessions(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+	aliceToken, bobToken := room.SessionToken(alice), room.SessionToken(bob)
+
+	// alice в сети, bob нет
+	pc := &PlayerConnection{send: make(chan interface{}, 4), player: room.GetPlayer(alice), room: room}
+	room.connMu.Lock()
+	room.Connections[alice] = pc
+	room.connMu.Unlock()
+
+	setRoomSettings(t, room, `{"password": "secret"}`)
+
+	if room.VerifySession(alice, aliceToken) || room.VerifySession(bob, bobToken) {
+		t.Fatal("old session token is valid after the password change")
+	}
+
+	var renewed *WSSessionTokenResponse
+	for len(pc.send) > 0 {
+		if msg, ok := (<-pc.send).(WSSessionTokenResponse); ok {
+			renewed = &msg
+		}
+	}
+	if renewed == nil {
+		t.Fatal("connected player did not get a new token")
+	}
+	if !room.VerifySession(alice, renewed.Token) {
+		t.Fatal("renewed token is not valid")
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import "strings"
+
+// Ограничения на то, что загадавший может добавить к персонажу
+const (
+	maxCharacterAliases  = 10
+	maxAliasLength       = 100
+	maxDescriptionLength = 500
+)
+
+// CharacterInfo что загадавший добавил к персонажу: другие имена, которые
+// тоже засчитываются при угадывании, и описание. Скрыто от владельца так же,
+// как сам персонаж.
+type CharacterInfo struct {
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// cleanAliases убирает пустые и повторяющиеся имена, в том числе совпадающие
+// с основным
+func cleanAliases(character string, aliases []string) []string {
+	seen := map[string]bool{normalizeGuess(character): true}
+	cleaned := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		key := normalizeGuess(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, alias)
+	}
+	return cleaned
+}
+
+// answersLocked все имена, под которыми игрок может угадать своего
+// персонажа. Вызывается под dataMu.
+func (r *Room) answersLocked(playerID string) []string {
+	answers := []string{r.Characters[playerID]}
+	return append(answers, r.CharacterInfo[playerID].Aliases...)
+}
+
+// visibleCharacterInfoLocked то же, что visibleCharactersLocked, для
+// альтернативных имён и описаний. Вызывается под dataMu.
+func (r *Room) visibleCharacterInfoLocked(playerID string) map[string]CharacterInfo {
+	visible := make(map[string]CharacterInfo, len(r.CharacterInfo))
+	for pid, info := range r.CharacterInfo {
+		if pid != playerID || r.revealedToOwnerLocked(pid) {
+			visible[pid] = info
+		}
+	}
+	return visible
+}
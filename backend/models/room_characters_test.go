@@ -0,0 +1,48 @@
+package models
+
+import "testing"
+
+func TestAliasesHiddenFromOwner(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	alice := playerID(t, room, "alice")
+	if err := room.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	room.dataMu.RLock()
+	owner := room.WhoMakeFor[alice].ID
+	room.dataMu.RUnlock()
+
+	msg := `{"type":"set_character","character":"Yoda","aliases":["Master Yoda","Йода"],"description":"Small and green"}`
+	if err := room.HandleMessage(alice, "alice", []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+
+	assigner := room.GetGameStateForPlayer(alice)
+	if info := assigner.CharacterInfo[owner]; len(info.Aliases) != 2 || info.Description != "Small and green" {
+		t.Fatalf("assigner sees %+v, want aliases and description", info)
+	}
+
+	state := room.GetGameStateForPlayer(owner)
+	if info, exists := state.CharacterInfo[owner]; exists {
+		t.Fatalf("owner sees own character info %+v", info)
+	}
+	if got := state.Characters[owner]; got != "?" {
+		t.Fatalf("owner sees own character %q, want ?", got)
+	}
+	room.dataMu.RLock()
+	events, _ := room.eventsSince(owner, 0)
+	room.dataMu.RUnlock()
+	for _, event := range events {
+		if set, ok := event.Payload.(WSSetCharacterResponse); ok && set.PlayerID == owner && (set.Character != "?" || len(set.Aliases) > 0) {
+			t.Fatalf("replay reveals the character to its owner: %+v", set)
+		}
+	}
+
+	// После игры персонаж со всеми именами виден и владельцу
+	if err := room.EndGame(GameOverHostEnded); err != nil {
+		t.Fatal(err)
+	}
+	if info := room.GetGameStateForPlayer(owner).CharacterInfo[owner]; len(info.Aliases) != 2 {
+		t.Fatalf("owner after the game sees %+v, want the aliases", info)
+	}
+}
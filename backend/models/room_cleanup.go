@@ -13,8 +13,17 @@ func (r *Room) Close() {
 		close(pc.send)
 	}
 	r.Connections = make(map[string]*PlayerConnection)
+	for _, timer := range r.graceTimers {
+		timer.Stop()
+	}
+	r.graceTimers = make(map[string]Timer)
 	r.connMu.Unlock()
-	
+
+	r.dataMu.Lock()
+	r.stopVoteTimersLocked()
+	r.stopTimersLocked()
+	r.dataMu.Unlock()
+
 	close(r.snapshotRequests)
 }
 
@@ -24,34 +33,27 @@ func CleanupOldRooms() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		mu.Lock()
 		now := time.Now()
-		for code, room := range rooms {
-			if now.Sub(room.CreatedAt) > 2*time.Hour {
-				log.Printf("Cleaning up room %s", code)
-				
-				// Закрываем комнату (все соединения и каналы)
-				room.Close()
-				
-				// Удаляем из глобального хранилища
-				delete(rooms, code)
+		for _, room := range store.Rooms() {
+			if now.Sub(room.CreatedAt) > RoomTTL {
+				log.Printf("Cleaning up room %s", room.Code)
+				DeleteRoom(room.Code)
 			}
 		}
-		mu.Unlock()
 	}
 }
 
 // DeleteRoom удаляет комнату (можно вызывать вручную)
 func DeleteRoom(code string) bool {
-	mu.Lock()
-	defer mu.Unlock()
-	
-	room, exists := rooms[code]
-	if !exists {
+	room, err := store.Delete(code)
+	if err != nil {
+		log.Printf("Error deleting room %s: %v", code, err)
+	}
+	if room == nil {
 		return false
 	}
-	
+
+	// Закрываем комнату (все соединения и каналы)
 	room.Close()
-	delete(rooms, code)
 	return true
 }
@@ -12,6 +12,7 @@ type PlayerConnection struct {
 	send   chan interface{}
 	player *Player
 	room   *Room
+	limits *connectionLimiter
 }
 
 func NewPlayerConnection(conn *websocket.Conn, player *Player, room *Room) *PlayerConnection {
@@ -20,6 +21,29 @@ func NewPlayerConnection(conn *websocket.Conn, player *Player, room *Room) *Play
 		send:   make(chan interface{}, 256),
 		player: player,
 		room:   room,
+		limits: newConnectionLimiter(),
+	}
+}
+
+// enqueue ставит сообщение в очередь соединения, не блокируясь. Вызывается
+// под connMu, пока соединение зарегистрировано в комнате.
+func (pc *PlayerConnection) enqueue(msg interface{}) {
+	select {
+	case pc.send <- msg:
+	default:
+		log.Printf("Channel full for player %s", pc.player.ID)
+	}
+}
+
+// reply отправляет ответ именно в это соединение: если игрок уже
+// переподключился, ошибки и закрытие старого сокета новый не получит
+func (pc *PlayerConnection) reply(msg interface{}) {
+	pc.room.connMu.RLock()
+	defer pc.room.connMu.RUnlock()
+
+	// send закрывается под connMu вместе с удалением из Connections
+	if pc.room.Connections[pc.player.ID] == pc {
+		pc.enqueue(msg)
 	}
 }
 
@@ -31,6 +55,11 @@ func (pc *PlayerConnection) writePump() {
 	for message := range pc.send {
 		pc.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 
+		if frame, ok := message.(closeFrame); ok {
+			pc.conn.WriteMessage(websocket.CloseMessage, frame.message())
+			return
+		}
+
 		if err := pc.conn.WriteJSON(message); err != nil {
 			log.Printf("Error writing to %s: %v", pc.player.Name, err)
 			return
@@ -40,17 +69,20 @@ func (pc *PlayerConnection) writePump() {
 	pc.conn.WriteMessage(websocket.CloseMessage, []byte{})
 }
 
-func (pc *PlayerConnection) readPump() {
+// ReadPump единственный читатель сокета, блокирует до закрытия соединения
+func (pc *PlayerConnection) ReadPump() {
 	defer func() {
 		pc.conn.Close()
 	}()
 
+	pc.conn.SetReadLimit(readLimit)
 	pc.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	pc.conn.SetPongHandler(func(string) error {
 		pc.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 		return nil
 	})
 
+	closing := false
 	for {
 		_, msgBytes, err := pc.conn.ReadMessage()
 		if err != nil {
@@ -58,38 +90,38 @@ func (pc *PlayerConnection) readPump() {
 				log.Printf("WebSocket error: %v", err)
 			}
 
-			// Отправляем уведомление о выходе
-			pc.room.sendMessageToAll(WSLeaveResponse{
-				Type:       "leave",
-				PlayerID:   pc.player.ID,
-				PlayerName: pc.player.Name,
-				Timestamp:  time.Now().Unix(),
-			})
+			// leave отправится, только если игрок не вернётся в течение окна
+			pc.room.ConnectionLost(pc)
 			break
 		}
 
-		if err := handleWSMessage(pc.room, msgBytes, pc.player.ID, pc.player.Name); err != nil {
-			log.Printf("Error handling message: %v", err)
-			pc.room.SendToPlayer(pc.player.ID, WSErrorResponse{
-				Type:      "error",
-				Error:     err.Error(),
-				Timestamp: time.Now().Unix(),
-			})
+		// После закрытия по лимитам только ждём ответный close
+		if closing {
+			continue
 		}
-	}
-}
-
-func (r *Room) AddConnection(playerID string, conn *websocket.Conn) {
-	player := r.GetPlayer(playerID)
 
-	r.connMu.Lock()
-	defer r.connMu.Unlock()
+		if err := pc.limits.check(msgBytes); err != nil {
+			pc.reply(NewErrorResponse(err))
+			if pc.limits.violation() {
+				log.Printf("Closing connection of %s: %v", pc.player.Name, err)
+				closing = true
+				pc.reply(closeFrame{
+					code:   websocket.ClosePolicyViolation,
+					reason: "message limits exceeded",
+				})
+				pc.conn.SetReadDeadline(time.Now().Add(closeGracePeriod))
+			}
+			continue
+		}
 
-	pc := NewPlayerConnection(conn, player, r)
-	r.Connections[playerID] = pc
+		// Клиент шлёт ping сообщениями, любое сообщение продлевает соединение
+		pc.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 
-	go pc.writePump()
-	go pc.readPump()
+		if err := pc.room.HandleMessage(pc.player.ID, pc.player.Name, msgBytes); err != nil {
+			log.Printf("Error handling message: %v", err)
+			pc.reply(NewErrorResponse(err))
+		}
+	}
 }
 
 func (r *Room) RemoveConnection(playerID string) {
@@ -100,4 +132,9 @@ func (r *Room) RemoveConnection(playerID string) {
 		close(pc.send)
 		delete(r.Connections, playerID)
 	}
+	if timer, exists := r.graceTimers[playerID]; exists {
+		timer.Stop()
+		delete(r.graceTimers, playerID)
+	}
+	delete(r.Presence, playerID)
 }
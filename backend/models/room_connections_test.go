@@ -0,0 +1,189 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// connectFake регистрирует соединение игрока без сокета, сообщения
+// копятся в send
+func connectFake(t *testing.T, room *Room, playerID string) *PlayerConnection {
+	t.Helper()
+	pc := &PlayerConnection{send: make(chan interface{}, 64), player: room.GetPlayer(playerID), room: room}
+
+	room.connMu.Lock()
+	room.Connections[playerID] = pc
+	room.Presence[playerID] = PresenceOnline
+	room.connMu.Unlock()
+	return pc
+}
+
+// dialRoom подключает игрока настоящим сокетом, как WebSocketHandler, и
+// возвращает клиентскую сторону. По окончании теста ждёт, пока серверная
+// сторона закончит ConnectionLost, чтобы она не пережила тест.
+func dialRoom(t *testing.T, room *Room, playerID string) *websocket.Conn {
+	t.Helper()
+	var upgrader websocket.Upgrader
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		pc, _, err := room.AddConnection(playerID, ws, 0)
+		if err != nil {
+			ws.Close()
+			return
+		}
+		pc.ReadPump()
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		<-done
+	})
+	readMessage(t, conn, "init")
+	return conn
+}
+
+// readMessage пропускает сообщения до первого типа msgType
+func readMessage(t *testing.T, conn *websocket.Conn, msgType string) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for %s: %v", msgType, err)
+		}
+		if msg["type"] == msgType {
+			return msg
+		}
+	}
+}
+
+// waitFor ждёт, пока горутины сокета доведут комнату до cond
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReplyGoesToOwnConnectionOnly(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	alice := playerID(t, room, "alice")
+
+	connect := func() *PlayerConnection {
+		return &PlayerConnection{send: make(chan interface{}, 4), player: room.GetPlayer(alice), room: room}
+	}
+	stale, current := connect(), connect()
+
+	room.connMu.Lock()
+	room.Connections[alice] = current
+	room.connMu.Unlock()
+
+	stale.reply(closeFrame{reason: "message limits exceeded"})
+	if len(current.send) != 0 {
+		t.Fatal("reply to the replaced socket reached the new one")
+	}
+	if len(stale.send) != 0 {
+		t.Fatal("reply was queued to a socket that is no longer registered")
+	}
+
+	current.reply(NewErrorResponse(badRequest("oops")))
+	if len(current.send) != 1 {
+		t.Fatal("reply did not reach the current socket")
+	}
+}
+
+func TestConnectionLimiterCheck(t *testing.T) {
+	l := newConnectionLimiter()
+
+	if err := l.check([]byte(`{"type":"chat","text":"` + strings.Repeat("a", maxMessageSize) + `"}`)); errorCode(err) != ErrCodeMessageTooLarge {
+		t.Fatalf("oversized message: err = %v, want %s", err, ErrCodeMessageTooLarge)
+	}
+
+	if err := l.check([]byte(`{"type":"chat","text":"hi"}`)); err != nil {
+		t.Fatalf("first chat: %v", err)
+	}
+	if err := l.check([]byte(`{"type":"chat","text":"hi again"}`)); errorCode(err) != ErrCodeRateLimited {
+		t.Fatalf("chat inside the cooldown: err = %v, want %s", err, ErrCodeRateLimited)
+	}
+	// Пауза для одного типа не мешает другим
+	if err := l.check([]byte(`{"type":"guess","character":"Yoda"}`)); err != nil {
+		t.Fatalf("guess after chat: %v", err)
+	}
+
+	// Общий лимит: запас messageBurst, три уже потрачены выше
+	var err error
+	for i := 0; i < messageBurst && err == nil; i++ {
+		err = l.check([]byte(`{"type":"ping"}`))
+	}
+	if errorCode(err) != ErrCodeRateLimited {
+		t.Fatalf("burst of pings: err = %v, want %s", err, ErrCodeRateLimited)
+	}
+}
+
+func TestConnectionLimiterViolations(t *testing.T) {
+	l := newConnectionLimiter()
+	for i := 1; i < maxViolations; i++ {
+		if l.violation() {
+			t.Fatalf("connection closed after %d violations", i)
+		}
+	}
+
+	// Старые нарушения забываются
+	l.lastViolation = time.Now().Add(-violationWindow - time.Second)
+	if l.violation() {
+		t.Fatal("violations outside the window were counted")
+	}
+	for i := 2; i < maxViolations; i++ {
+		l.violation()
+	}
+	if !l.violation() {
+		t.Fatalf("connection still open after %d violations in a row", maxViolations)
+	}
+}
+
+func TestReadPumpClosesAfterViolations(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	alice := playerID(t, room, "alice")
+	conn := dialRoom(t, room, alice)
+
+	oversized := []byte(`{"type":"chat","text":"` + strings.Repeat("a", maxMessageSize) + `"}`)
+	for i := 0; i < maxViolations; i++ {
+		if err := conn.WriteMessage(websocket.TextMessage, oversized); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < maxViolations; i++ {
+		if msg := readMessage(t, conn, "error"); msg["code"] != ErrCodeMessageTooLarge {
+			t.Fatalf("error %d = %v, want %s", i+1, msg, ErrCodeMessageTooLarge)
+		}
+	}
+
+	// Дальше только close с причиной, клиент отвечает своим close
+	var msg map[string]interface{}
+	err := conn.ReadJSON(&msg)
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Fatalf("after %d violations got %v, %v; want a policy violation close", maxViolations, msg, err)
+	}
+	waitFor(t, "the server to drop the connection", func() bool {
+		return presenceOf(room, alice) == PresenceReconnecting
+	})
+}
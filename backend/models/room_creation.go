@@ -2,16 +2,11 @@ package models
 
 import (
 	"crypto/rand"
+	"log"
 	"math/big"
-	"sync"
 	"time"
 )
 
-var (
-	rooms = make(map[string]*Room)
-	mu    sync.RWMutex
-)
-
 func GenerateRoomCode() string {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	code := make([]byte, 6)
@@ -22,38 +17,58 @@ func GenerateRoomCode() string {
 	return string(code)
 }
 
-func CreateRoom() *Room {
-	mu.Lock()
-	defer mu.Unlock()
-
-	code := GenerateRoomCode()
-	for {
-		if _, exists := rooms[code]; !exists {
-			break
-		}
-		code = GenerateRoomCode()
-	}
-
+func newRoom(code string, createdAt time.Time) *Room {
 	room := &Room{
-		Code:        code,
-		Players:     []Player{},
-		Started:     false,
-		WhoMakeFor:  make(map[string]Player),
-		Characters:  make(map[string]string),
-		Connections: make(map[string]*PlayerConnection),
-		CreatedAt:   time.Now(),
+		Code:             code,
+		Players:          []Player{},
+		Started:          false,
+		Phase:            PhaseLobby,
+		WhoMakeFor:       make(map[string]Player),
+		Characters:       make(map[string]string),
+		CharacterInfo:    make(map[string]CharacterInfo),
+		GuessCounts:      make(map[string]int),
+		WrongGuesses:     make(map[string]int),
+		GuessCooldowns:   make(map[string]time.Time),
+		SessionNonces:    make(map[string]string),
+		Scoreboard:       make(map[string]ScoreEntry),
+		Settings:         DefaultRoomSettings(),
+		Connections:      make(map[string]*PlayerConnection),
+		Presence:         make(map[string]PresenceState),
+		graceTimers:      make(map[string]Timer),
+		voteTimers:       make(map[string]Timer),
+		CreatedAt:        createdAt,
 		snapshotRequests: make(chan snapshotRequest, 10),
 	}
 
 	go room.snapshotWorker()
 
-	rooms[code] = room
 	return room
 }
 
+// CreateRoom создаёт комнату с уже проверенными настройками
+func CreateRoom(settings RoomSettings) (*Room, error) {
+	hash, _, err := settings.takePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		room := newRoom(GenerateRoomCode(), time.Now())
+		room.Settings = settings
+		room.PasswordHash = hash
+
+		added, err := store.Add(room)
+		if err != nil {
+			log.Printf("Error saving room %s: %v", room.Code, err)
+		}
+		if added {
+			return room, nil
+		}
+
+		room.Close()
+	}
+}
+
 func GetRoom(code string) (*Room, bool) {
-	mu.RLock()
-	defer mu.RUnlock()
-	room, exists := rooms[code]
-	return room, exists
+	return store.Get(code)
 }
@@ -0,0 +1,103 @@
+package models
+
+import "time"
+
+const (
+	defaultSuggestions = 3
+	maxSuggestions     = 10
+)
+
+// roomDeckLocked колода комнаты, nil если не задана или пропала.
+// Вызывается под dataMu.
+func (r *Room) roomDeckLocked() *Deck {
+	if r.Settings.Deck == "" {
+		return nil
+	}
+	deck, _ := GetDeck(r.Settings.Deck)
+	return deck
+}
+
+// checkAutoAssignLocked хватит ли карточек, чтобы раздать всем разных
+// персонажей. Вызывается под dataMu.
+func (r *Room) checkAutoAssignLocked() error {
+	if !r.Settings.AutoAssign {
+		return nil
+	}
+	deck := r.roomDeckLocked()
+	if deck == nil {
+		return badRequest("deck %q not found", r.Settings.Deck)
+	}
+	if players := r.playerCountLocked(); len(deck.Filter(r.Settings.deckFilter())) < players {
+		return badRequest("deck %q has fewer than %d matching characters", r.Settings.Deck, players)
+	}
+	return nil
+}
+
+// autoAssignCharacters раздаёт персонажей из колоды за всех загадывающих.
+// Рассылка и переход к угадыванию те же, что при ручном SetCharacter.
+func (r *Room) autoAssignCharacters() {
+	r.dataMu.RLock()
+	deck := r.roomDeckLocked()
+	if !r.Settings.AutoAssign || deck == nil || r.Phase != PhaseAssigning {
+		r.dataMu.RUnlock()
+		return
+	}
+	assigners := make([]string, 0, len(r.WhoMakeFor))
+	for _, player := range r.Players {
+		if _, exists := r.WhoMakeFor[player.ID]; exists {
+			assigners = append(assigners, player.ID)
+		}
+	}
+	entries := deck.Sample(len(assigners), r.Settings.deckFilter())
+	r.dataMu.RUnlock()
+
+	for i, assignerID := range assigners {
+		if i >= len(entries) {
+			break
+		}
+		r.SetCharacter(WSSetCharacterMessage{
+			PlayerID:  assignerID,
+			Character: entries[i].Name,
+			Aliases:   entries[i].Aliases,
+		})
+	}
+}
+
+// SuggestCharacters несколько случайных карточек для загадывающего. Без
+// колоды в комнате берутся карточки из всех загруженных колод.
+func (r *Room) SuggestCharacters(playerID string, count int, filter DeckFilter) error {
+	if count <= 0 {
+		count = defaultSuggestions
+	}
+	count = min(count, maxSuggestions)
+
+	r.dataMu.RLock()
+	_, isAssigner := r.WhoMakeFor[playerID]
+	deck := r.roomDeckLocked()
+	if filter == (DeckFilter{}) {
+		filter = r.Settings.deckFilter()
+	}
+	r.dataMu.RUnlock()
+
+	if !isAssigner {
+		return badRequest("you have nobody to make a character for")
+	}
+	if deck == nil {
+		deck = &Deck{}
+		for _, d := range Decks() {
+			deck.Entries = append(deck.Entries, d.Entries...)
+		}
+	}
+
+	candidates := deck.Sample(count, filter)
+	if len(candidates) == 0 {
+		return badRequest("no characters to suggest")
+	}
+
+	r.SendToPlayer(playerID, WSCharacterSuggestionsResponse{
+		Type:       "character_suggestions",
+		Candidates: candidates,
+		Timestamp:  time.Now().Unix(),
+	})
+	return nil
+}
@@ -0,0 +1,114 @@
+package models
+
+import "testing"
+
+// received сообщения, накопившиеся в очереди соединения, без обёртки Event
+func received(pc *PlayerConnection) []interface{} {
+	var messages []interface{}
+	for {
+		select {
+		case msg := <-pc.send:
+			if event, ok := msg.(Event); ok {
+				msg = event.Payload
+			}
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+func TestSuggestionsReachOnlyAssigner(t *testing.T) {
+	useMemoryStore(t)
+	deck := &Deck{ID: NewDeckID(), Entries: []DeckEntry{{Name: "Yoda"}, {Name: "Leia"}, {Name: "Han Solo"}}}
+	if err := SaveDeck(deck); err != nil {
+		t.Fatal(err)
+	}
+
+	room := newTestRoom(t, "alice", "bob", "carol")
+	alice := playerID(t, room, "alice")
+	setRoomSettings(t, room, `{"deck": "`+deck.ID+`"}`)
+	if err := room.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	connections := make(map[string]*PlayerConnection)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		connections[name] = connectFake(t, room, playerID(t, room, name))
+	}
+
+	if err := room.HandleMessage(alice, "alice", []byte(`{"type":"suggest_character","count":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	for name, pc := range connections {
+		suggestions := 0
+		for _, msg := range received(pc) {
+			if resp, ok := msg.(WSCharacterSuggestionsResponse); ok {
+				suggestions++
+				if len(resp.Candidates) != 2 {
+					t.Errorf("%s got %d candidates, want 2", name, len(resp.Candidates))
+				}
+			}
+		}
+		want := 0
+		if name == "alice" {
+			want = 1
+		}
+		if suggestions != want {
+			t.Errorf("%s got %d suggestion messages, want %d", name, suggestions, want)
+		}
+	}
+
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+	for _, event := range room.Events {
+		if _, ok := event.Payload.(WSCharacterSuggestionsResponse); ok {
+			t.Fatal("suggestions were written to the room log")
+		}
+	}
+}
+
+func TestAutoAssignHidesCharacterFromOwner(t *testing.T) {
+	useMemoryStore(t)
+	deck := &Deck{ID: NewDeckID(), Entries: []DeckEntry{
+		{Name: "Yoda", Aliases: []string{"Master Yoda"}}, {Name: "Leia"}, {Name: "Han Solo"},
+	}}
+	if err := SaveDeck(deck); err != nil {
+		t.Fatal(err)
+	}
+
+	room := newTestRoom(t, "alice", "bob", "carol")
+	setRoomSettings(t, room, `{"deck": "`+deck.ID+`", "autoAssign": true}`)
+	connections := make(map[string]*PlayerConnection)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		connections[playerID(t, room, name)] = connectFake(t, room, playerID(t, room, name))
+	}
+
+	if err := room.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	if phase := roomPhase(room); phase != PhaseGuessing {
+		t.Fatalf("phase = %s after auto assignment, want %s", phase, PhaseGuessing)
+	}
+
+	for id, pc := range connections {
+		seen := 0
+		for _, msg := range received(pc) {
+			set, ok := msg.(WSSetCharacterResponse)
+			if !ok {
+				continue
+			}
+			if set.PlayerID == id && (set.Character != "?" || len(set.Aliases) > 0) {
+				t.Errorf("owner %s was told the character %+v", id, set)
+			}
+			if set.PlayerID != id && set.Character != "?" {
+				seen++
+			}
+		}
+		if seen != 2 {
+			t.Errorf("player %s saw %d other characters, want 2", id, seen)
+		}
+		if got := room.GetGameStateForPlayer(id).Characters[id]; got != "?" {
+			t.Errorf("player %s sees own character %q in the state", id, got)
+		}
+	}
+}
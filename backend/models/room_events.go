@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event запись журнала комнаты. Каждое разосланное всем сообщение получает
+// монотонно растущий номер Seq и серверный ID, по которым клиент может
+// дозапросить пропущенное после переподключения.
+type Event struct {
+	Seq     int64
+	ID      string
+	Payload interface{}
+	// Hidden игроки, которым событие не показывается
+	Hidden []string
+}
+
+// MarshalJSON добавляет seq и eventId в сам объект сообщения, чтобы клиенты
+// по-прежнему видели плоский {"type": ...}
+func (e Event) MarshalJSON() ([]byte, error) {
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < 2 || payload[0] != '{' {
+		return nil, fmt.Errorf("event payload must be a JSON object")
+	}
+
+	data := []byte(fmt.Sprintf(`{"seq":%d,"eventId":%q`, e.Seq, e.ID))
+	if len(payload) > 2 {
+		data = append(data, ',')
+	}
+	return append(data, payload[1:]...), nil
+}
+
+// visibleTo повторяет правила видимости при рассылке: игрок не видит
+// исключённые для него события и раскрытие собственного персонажа
+func (e Event) visibleTo(playerID string) bool {
+	for _, id := range e.Hidden {
+		if id == playerID {
+			return false
+		}
+	}
+
+	switch msg := e.Payload.(type) {
+	case WSSetCharacterResponse:
+		if msg.PlayerID == playerID && msg.Character != "?" {
+			return false
+		}
+	}
+	return true
+}
+
+// MaxEvents сколько последних событий хранит журнал. Клиент, который
+// отстал сильнее, получает resync_required и заново запрашивает комнату.
+const MaxEvents = 500
+
+func newEventID() string {
+	return hex.EncodeToString(mustRandomBytes(8))
+}
+
+// appendEvent записывает сообщение в журнал, вызывается под eventMu
+func (r *Room) appendEvent(msg interface{}, hidden []string) Event {
+	r.dataMu.Lock()
+	defer r.dataMu.Unlock()
+
+	r.LastSeq++
+	event := Event{
+		Seq:     r.LastSeq,
+		ID:      newEventID(),
+		Payload: msg,
+		Hidden:  hidden,
+	}
+	r.Events = append(r.Events, event)
+	if len(r.Events) > MaxEvents {
+		r.Events = r.Events[len(r.Events)-MaxEvents:]
+	}
+	return event
+}
+
+// eventsSince события после since, видимые игроку. truncated, если часть
+// событий после since уже вытеснена из журнала или журнал начат заново.
+// Вызывается под dataMu.
+func (r *Room) eventsSince(playerID string, since int64) (events []Event, truncated bool) {
+	oldest := r.LastSeq + 1
+	if len(r.Events) > 0 {
+		oldest = r.Events[0].Seq
+	}
+
+	events = make([]Event, 0, len(r.Events))
+	for _, event := range r.Events {
+		if event.Seq > since && event.visibleTo(playerID) {
+			events = append(events, event)
+		}
+	}
+	return events, since+1 < oldest
+}
+
+// replayLocked ставит в очередь соединения события после since. Если
+// их уже нет в журнале, вместо них уходит resync_required.
+// Вызывается под eventMu и connMu.
+func (r *Room) replayLocked(pc *PlayerConnection, since int64) {
+	r.dataMu.RLock()
+	events, truncated := r.eventsSince(pc.player.ID, since)
+	lastSeq := r.LastSeq
+	r.dataMu.RUnlock()
+
+	if truncated {
+		pc.enqueue(WSResyncRequiredResponse{
+			Type:      "resync_required",
+			LastSeq:   lastSeq,
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	for _, event := range events {
+		pc.enqueue(event)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import "testing"
+
+func TestEventLogIsCapped(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	alice := playerID(t, room, "alice")
+
+	for i := 0; i < MaxEvents+10; i++ {
+		room.Broadcast(WSChatResponse{Type: "chat", Text: "hi"})
+	}
+
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+
+	if len(room.Events) != MaxEvents {
+		t.Fatalf("len(Events) = %d, want %d", len(room.Events), MaxEvents)
+	}
+	if first := room.Events[0].Seq; first != 11 {
+		t.Fatalf("oldest event seq = %d, want 11", first)
+	}
+
+	tests := []struct {
+		since         int64
+		wantEvents    int
+		wantTruncated bool
+	}{
+		{since: 0, wantEvents: MaxEvents, wantTruncated: true},
+		{since: 5, wantEvents: MaxEvents, wantTruncated: true},
+		{since: 10, wantEvents: MaxEvents, wantTruncated: false},
+		{since: room.LastSeq - 1, wantEvents: 1, wantTruncated: false},
+		{since: room.LastSeq, wantEvents: 0, wantTruncated: false},
+	}
+	for _, tt := range tests {
+		events, truncated := room.eventsSince(alice, tt.since)
+		if len(events) != tt.wantEvents || truncated != tt.wantTruncated {
+			t.Errorf("eventsSince(%d) = %d events, truncated %v; want %d, %v",
+				tt.since, len(events), truncated, tt.wantEvents, tt.wantTruncated)
+		}
+	}
+}
+
+func TestEventsSinceAfterReset(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	alice := playerID(t, room, "alice")
+
+	room.Broadcast(WSChatResponse{Type: "chat", Text: "hi"})
+	room.Broadcast(WSChatResponse{Type: "chat", Text: "hi"})
+
+	room.dataMu.Lock()
+	room.Events = nil
+	room.dataMu.Unlock()
+
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+
+	if _, truncated := room.eventsSince(alice, 1); !truncated {
+		t.Error("events dropped by a reset are not reported as truncated")
+	}
+	if _, truncated := room.eventsSince(alice, 2); truncated {
+		t.Error("client that saw every event is asked to resync")
+	}
+}
@@ -8,33 +8,51 @@ func (r *Room) swapPlayers(index1 int, index2 int) {
 	r.Players[index2] = player1
 }
 
+// calcWhoMakeFor каждый игрок загадывает следующему по кругу, зрители
+// в раздаче не участвуют
 func (r *Room) calcWhoMakeFor() {
-	for i, player := range r.Players {
-		if i+1 == len(r.Players) {
-			r.WhoMakeFor[player.ID] = r.Players[0]
+	players := make([]Player, 0, len(r.Players))
+	for _, player := range r.Players {
+		if !player.IsSpectator {
+			players = append(players, player)
+		}
+	}
+
+	for i, player := range players {
+		if i+1 == len(players) {
+			r.WhoMakeFor[player.ID] = players[0]
 			continue
 		}
-		r.WhoMakeFor[player.ID] = r.Players[i+1]
+		r.WhoMakeFor[player.ID] = players[i+1]
 	}
 }
 
-func (r *Room) StartGame() {
+func (r *Room) StartGame() error {
 	r.dataMu.Lock()
 
-	if r.Started {
+	if err := r.checkAutoAssignLocked(); err != nil {
 		r.dataMu.Unlock()
-		return
+		return err
+	}
+	prev, err := r.setPhaseLocked(PhaseAssigning)
+	if err != nil {
+		r.dataMu.Unlock()
+		return err
 	}
 
 	r.calcWhoMakeFor()
-	r.Started = true
+	r.RoundPlayers = r.playerCountLocked()
+	r.resetQuestionsLocked()
+	// Журнал начинается заново, но номера событий продолжают расти
+	r.Events = nil
 	r.dataMu.Unlock()
 
-	r.Messages = make([]interface{}, 0)
-
 	r.sendMessageToAll(WSGameStartedResponse{
 		Type:      "game_started",
 		Text:      "Game has started!",
 		Timestamp: time.Now().Unix(),
 	})
+	r.broadcastPhase(prev, PhaseAssigning)
+	r.autoAssignCharacters()
+	return nil
 }
@@ -0,0 +1,126 @@
+package models
+
+import "time"
+
+const (
+	GameOverAllGuessed = "all_guessed"
+	GameOverHostEnded  = "host_ended"
+	GameOverTimeUp     = "time_up"
+)
+
+// WinRecord кто и когда отгадал своего персонажа
+type WinRecord struct {
+	PlayerID string    `json:"playerId"`
+	WonAt    time.Time `json:"wonAt"`
+}
+
+type RankingEntry struct {
+	PlayerID   string         `json:"playerId"`
+	PlayerName string         `json:"playerName"`
+	Position   int            `json:"position"`
+	Won        bool           `json:"won"`
+	Eliminated bool           `json:"eliminated"`
+	Guesses    int            `json:"guesses"`
+	DurationMs int64          `json:"durationMs"`
+	Character  string         `json:"character"`
+	Score      ScoreBreakdown `json:"score"`
+}
+
+// rankingLocked итоговая таблица: победители в порядке отгадывания,
+// затем остальные на общем последнем месте. Вызывается под dataMu.
+func (r *Room) rankingLocked(endedAt time.Time) []RankingEntry {
+	ranking := make([]RankingEntry, 0, len(r.Players))
+	ranked := make(map[string]bool, len(r.Winners))
+
+	entry := func(player Player, position int, finishedAt time.Time) RankingEntry {
+		duration := int64(0)
+		if !r.GuessingStartedAt.IsZero() {
+			duration = finishedAt.Sub(r.GuessingStartedAt).Milliseconds()
+		}
+		return RankingEntry{
+			PlayerID:   player.ID,
+			PlayerName: player.Name,
+			Position:   position,
+			Won:        player.IsWinner,
+			Eliminated: player.IsEliminated,
+			Guesses:    r.GuessCounts[player.ID],
+			DurationMs: duration,
+			Character:  r.Characters[player.ID],
+		}
+	}
+
+	for _, win := range r.Winners {
+		index := r.findPlayerById(win.PlayerID)
+		if index == -1 {
+			continue
+		}
+		ranking = append(ranking, entry(r.Players[index], len(ranking)+1, win.WonAt))
+		ranked[win.PlayerID] = true
+	}
+
+	lastPosition := len(ranking) + 1
+	for _, player := range r.Players {
+		if !ranked[player.ID] && !player.IsSpectator {
+			ranking = append(ranking, entry(player, lastPosition, endedAt))
+		}
+	}
+
+	return ranking
+}
+
+// EndGame завершает игру и рассылает итоговую таблицу. Персонажи
+// в game_over раскрываются всем, в том числе их владельцам.
+func (r *Room) EndGame(reason string) error {
+	now := clock.Now()
+
+	r.dataMu.Lock()
+	prev, err := r.setPhaseLocked(PhaseFinished)
+	if err != nil {
+		r.dataMu.Unlock()
+		return err
+	}
+	r.FinishedAt = now
+
+	ranking := r.rankingLocked(now)
+	r.scoreRoundLocked(ranking)
+	r.addRoundLocked(ranking)
+	scoreboard := r.scoreboardLocked()
+	characters := make(map[string]string, len(r.Characters))
+	for pid, character := range r.Characters {
+		characters[pid] = character
+	}
+	characterInfo := r.visibleCharacterInfoLocked("")
+	r.dataMu.Unlock()
+
+	r.broadcastPhase(prev, PhaseFinished)
+	r.sendMessageToAll(WSGameOverResponse{
+		Type:          "game_over",
+		Reason:        reason,
+		Ranking:       ranking,
+		Scoreboard:    scoreboard,
+		Characters:    characters,
+		CharacterInfo: characterInfo,
+		Timestamp:     now.Unix(),
+	})
+	return nil
+}
+
+// checkGameOver завершает игру, когда угадывать остался один игрок.
+// Правило зависит от состава в начале раунда, а не от того, сколько
+// игроков осталось после ухода: одиночный раунд длится, пока игрок
+// не угадает, раунд на нескольких заканчивается на последнем.
+func (r *Room) checkGameOver() {
+	r.dataMu.RLock()
+	remaining := 0
+	for _, player := range r.Players {
+		if player.inGame() {
+			remaining++
+		}
+	}
+	over := r.Phase == PhaseGuessing && (remaining == 0 || (r.RoundPlayers >= 2 && remaining <= 1))
+	r.dataMu.RUnlock()
+
+	if over {
+		r.EndGame(GameOverAllGuessed)
+	}
+}
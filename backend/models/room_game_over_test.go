@@ -0,0 +1,54 @@
+package models
+
+import "testing"
+
+// startGuessing начинает раунд и сразу переводит его в угадывание
+func startGuessing(t *testing.T, room *Room) {
+	t.Helper()
+	if err := room.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	room.dataMu.Lock()
+	room.Phase = PhaseGuessing
+	room.dataMu.Unlock()
+}
+
+func TestGameEndsWhenOthersAreRemoved(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob", "carol")
+	startGuessing(t, room)
+
+	room.RemovePlayerWithNotification(playerID(t, room, "bob"))
+	if phase := roomPhase(room); phase != PhaseGuessing {
+		t.Fatalf("phase = %s with two players still guessing", phase)
+	}
+
+	room.RemovePlayerWithNotification(playerID(t, room, "carol"))
+	if phase := roomPhase(room); phase != PhaseFinished {
+		t.Fatalf("phase = %s after all but one player left, want %s", phase, PhaseFinished)
+	}
+}
+
+func TestSinglePlayerRoundEndsOnlyOnGuess(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	startGuessing(t, room)
+
+	room.checkGameOver()
+	if phase := roomPhase(room); phase != PhaseGuessing {
+		t.Fatalf("single-player round ended without a winner: phase = %s", phase)
+	}
+
+	room.AddWinner(WSAddWinnerMessage{WinnerID: playerID(t, room, "alice")})
+	if phase := roomPhase(room); phase != PhaseFinished {
+		t.Fatalf("phase = %s after the only player guessed, want %s", phase, PhaseFinished)
+	}
+}
+
+func TestGameEndsWhenLastButOneGuesses(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	startGuessing(t, room)
+
+	room.AddWinner(WSAddWinnerMessage{WinnerID: playerID(t, room, "alice")})
+	if phase := roomPhase(room); phase != PhaseFinished {
+		t.Fatalf("phase = %s, want %s", phase, PhaseFinished)
+	}
+}
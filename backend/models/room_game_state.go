@@ -1,16 +1,33 @@
 package models
 
-import "log"
+import "time"
 
 type GameState struct {
-	Type         string            `json:"type"`
-	Players      []Player          `json:"players"`
-	Started      bool              `json:"started"`
-	Characters   map[string]string `json:"characters"`
-	OpponentName string            `json:"opponentName"`
+	Type           string                   `json:"type"`
+	HostID         string                   `json:"hostId"`
+	Players        []Player                 `json:"players"`
+	Presence       map[string]PresenceState `json:"presence"`
+	Started        bool                     `json:"started"`
+	Phase          GamePhase                `json:"phase"`
+	Settings       RoomSettings             `json:"settings"`
+	ActivePlayerID string                   `json:"activePlayerId"`
+	TurnRemaining  int64                    `json:"turnRemainingMs"`
+	GameRemaining  int64                    `json:"gameRemainingMs"`
+	Paused         bool                     `json:"paused"`
+	PausedAt       int64                    `json:"pausedAt,omitempty"`
+	WrongGuesses   map[string]int           `json:"wrongGuesses"`
+	Characters     map[string]string        `json:"characters"`
+	CharacterInfo  map[string]CharacterInfo `json:"characterInfo"`
+	Questions      []Question               `json:"questions"`
+	Round          int                      `json:"round"`
+	Scoreboard     []ScoreEntry             `json:"scoreboard"`
+	OpponentName   string                   `json:"opponentName"`
+	LastSeq        int64                    `json:"lastSeq"`
 }
 
 func (r *Room) GetGameStateForPlayer(playerID string) GameState {
+	presence := r.presenceSnapshot()
+
 	r.dataMu.RLock()
 	defer r.dataMu.RUnlock()
 
@@ -22,35 +39,73 @@ func (r *Room) GetGameStateForPlayer(playerID string) GameState {
 		}
 	}
 
-	opponentIndex := (userIndex + 1) % len(r.Players)
-
-	visibleCharacters := make(map[string]string)
-	for pid, char := range r.Characters {
-		if pid != playerID {
-			visibleCharacters[pid] = char
-		} else if char != "" {
-			visibleCharacters[pid] = "?"
-		}
+	// Игрока могли удалить, пока открывался сокет, комната может быть пуста
+	opponentName := ""
+	if len(r.Players) > 0 {
+		opponentName = r.Players[(userIndex+1)%len(r.Players)].Name
+	}
+	if target, exists := r.WhoMakeFor[playerID]; exists {
+		opponentName = target.Name
 	}
 
+	players := make([]Player, len(r.Players))
+	copy(players, r.Players)
+
+	visibleCharacters := r.visibleCharactersLocked(playerID)
+
 	return GameState{
-		Type:         "init",
-		Players:      r.Players,
-		Started:      r.Started,
-		Characters:   visibleCharacters,
-		OpponentName: r.Players[opponentIndex].Name,
+		Type:           "init",
+		HostID:         r.HostID,
+		Players:        players,
+		Presence:       presence,
+		Started:        r.Started,
+		Phase:          r.Phase,
+		Settings:       r.Settings.public(r.PasswordHash),
+		ActivePlayerID: r.ActivePlayerID,
+		TurnRemaining:  r.remainingLocked(r.TurnDeadline),
+		GameRemaining:  r.remainingLocked(r.GameDeadline),
+		Paused:         r.Paused,
+		PausedAt:       pausedAtUnix(r.PausedAt),
+		WrongGuesses:   r.wrongGuessesLocked(),
+		Characters:     visibleCharacters,
+		CharacterInfo:  r.visibleCharacterInfoLocked(playerID),
+		Questions:      r.questionsLocked(),
+		Round:          r.Round,
+		Scoreboard:     r.scoreboardLocked(),
+		OpponentName:   opponentName,
+		LastSeq:        r.LastSeq,
 	}
 }
 
-func (r *Room) SendGameStateToPlayer(playerID string, state GameState) {
-	r.connMu.RLock()
-	defer r.connMu.RUnlock()
+// revealedToOwnerLocked может ли игрок видеть своего персонажа.
+// Вызывается под dataMu.
+func (r *Room) revealedToOwnerLocked(playerID string) bool {
+	if r.Phase == PhaseFinished {
+		return true
+	}
+	index := r.findPlayerById(playerID)
+	return index != -1 && r.Players[index].IsEliminated
+}
+
+// pausedAtUnix время постановки на паузу, 0 если игра не на паузе
+func pausedAtUnix(pausedAt time.Time) int64 {
+	if pausedAt.IsZero() {
+		return 0
+	}
+	return pausedAt.Unix()
+}
 
-	if pc, exists := r.Connections[playerID]; exists {
-		select {
-		case pc.send <- state:
-		default:
-			log.Printf("Channel full for player %s", playerID)
+// visibleCharactersLocked персонажи глазами игрока: свой скрыт до конца игры
+// или до выбывания.
+// Вызывается под dataMu.
+func (r *Room) visibleCharactersLocked(playerID string) map[string]string {
+	visibleCharacters := make(map[string]string)
+	for pid, char := range r.Characters {
+		if pid != playerID || r.revealedToOwnerLocked(pid) {
+			visibleCharacters[pid] = char
+		} else if char != "" {
+			visibleCharacters[pid] = "?"
 		}
 	}
+	return visibleCharacters
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"log"
+	"strings"
 	"time"
 )
 
@@ -16,11 +17,23 @@ func (r *Room) RemovePlayer(playerID string) bool {
 	}
 
 	r.Players = append(r.Players[:playerIndex], r.Players[playerIndex+1:]...)
+	// Токены удалённого игрока больше не действуют
+	delete(r.SessionNonces, playerID)
 	delete(r.Characters, playerID)
+	delete(r.CharacterInfo, playerID)
+
+	// Замыкаем цепочку: кто загадывал ушедшему, теперь загадывает тому,
+	// кому загадывал ушедший
+	removedTarget, hadTarget := r.WhoMakeFor[playerID]
 	delete(r.WhoMakeFor, playerID)
 
 	for pid, targetPlayer := range r.WhoMakeFor {
-		if targetPlayer.ID == playerID {
+		if targetPlayer.ID != playerID {
+			continue
+		}
+		if hadTarget && removedTarget.ID != pid {
+			r.WhoMakeFor[pid] = removedTarget
+		} else {
 			delete(r.WhoMakeFor, pid)
 		}
 	}
@@ -35,6 +48,11 @@ func (r *Room) RemovePlayerWithNotification(playerID string) bool {
 	}
 	playerName := player.Name
 
+	r.dataMu.RLock()
+	index := r.findPlayerById(playerID)
+	wasHost := r.HostID == playerID
+	r.dataMu.RUnlock()
+
 	if !r.RemovePlayer(playerID) {
 		return false
 	}
@@ -47,23 +65,42 @@ func (r *Room) RemovePlayerWithNotification(playerID string) bool {
 		Timestamp:  time.Now().Unix(),
 	})
 
+	if wasHost {
+		r.transferHost(playerID, index)
+	}
+
+	r.passTurnFromIndex(playerID, index)
+	r.advanceIfAssigned()
+	r.checkGameOver()
+
 	r.RemoveConnection(playerID)
 	return true
 }
 
-func (r *Room) SetCharacter(msg WSSetCharacterMessage) {
+func (r *Room) SetCharacter(msg WSSetCharacterMessage) error {
 	r.dataMu.Lock()
-	characterFor := r.WhoMakeFor[msg.PlayerID]
+	characterFor, exists := r.WhoMakeFor[msg.PlayerID]
+	if !exists {
+		r.dataMu.Unlock()
+		return badRequest("you have nobody to make a character for")
+	}
+	info := CharacterInfo{
+		Aliases:     cleanAliases(msg.Character, msg.Aliases),
+		Description: strings.TrimSpace(msg.Description),
+	}
 	r.Characters[characterFor.ID] = msg.Character
+	r.CharacterInfo[characterFor.ID] = info
 	r.dataMu.Unlock()
 
 	// Сообщение для всех кроме владельца персонажа
 	msgForOthers := WSSetCharacterResponse{
-		Type:      "set_character",
-		PlayerID:  characterFor.ID,
-		Character: msg.Character,
-		Text:      characterFor.Name + " is a " + msg.Character,
-		Timestamp: time.Now().Unix(),
+		Type:        "set_character",
+		PlayerID:    characterFor.ID,
+		Character:   msg.Character,
+		Aliases:     info.Aliases,
+		Description: info.Description,
+		Text:        characterFor.Name + " is a " + msg.Character,
+		Timestamp:   time.Now().Unix(),
 	}
 
 	r.sendMessageToAllWithExceptions(msgForOthers, []string{characterFor.ID})
@@ -78,6 +115,9 @@ func (r *Room) SetCharacter(msg WSSetCharacterMessage) {
 	}
 
 	r.SendToPlayer(characterFor.ID, msgForOwner)
+
+	r.advanceIfAssigned()
+	return nil
 }
 
 func (r *Room) AddWinner(msg WSAddWinnerMessage) {
@@ -96,6 +136,10 @@ func (r *Room) AddWinner(msg WSAddWinnerMessage) {
 
 	r.Players[playerIndex].IsWinner = true
 	winnerName := r.Players[playerIndex].Name
+	r.Winners = append(r.Winners, WinRecord{
+		PlayerID: msg.WinnerID,
+		WonAt:    clock.Now(),
+	})
 	r.dataMu.Unlock()
 
 	r.sendMessageToAll(WSAddWinnerResponse{
@@ -104,4 +148,7 @@ func (r *Room) AddWinner(msg WSAddWinnerMessage) {
 		Text:      winnerName + " won the game!",
 		Timestamp: time.Now().Unix(),
 	})
+
+	r.passTurn(msg.WinnerID)
+	r.checkGameOver()
 }
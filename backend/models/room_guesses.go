@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// GuessPenalty что происходит с игроком после неверной догадки
+type GuessPenalty string
+
+const (
+	// PenaltyLoseTurn ход переходит к следующему игроку
+	PenaltyLoseTurn GuessPenalty = "lose_turn"
+	// PenaltyCooldown следующая догадка возможна только через guessCooldownSec
+	PenaltyCooldown GuessPenalty = "cooldown"
+	// PenaltyElimination после maxWrongGuesses неверных догадок игрок выбывает
+	PenaltyElimination GuessPenalty = "elimination"
+)
+
+const (
+	DefaultGuessCooldown   = 30 * time.Second
+	DefaultMaxWrongGuesses = 3
+)
+
+const (
+	ErrCodeGuessCooldown = "guess_cooldown"
+	ErrCodeEliminated    = "eliminated"
+)
+
+func (p GuessPenalty) Valid() bool {
+	switch p {
+	case PenaltyLoseTurn, PenaltyCooldown, PenaltyElimination:
+		return true
+	}
+	return false
+}
+
+// wrongGuessLocked применяет наказание за неверную догадку, возвращает true,
+// если игрок выбыл. Вызывается под dataMu.
+func (r *Room) wrongGuessLocked(playerID string) bool {
+	r.WrongGuesses[playerID]++
+
+	switch r.Settings.WrongGuessPenalty {
+	case PenaltyCooldown:
+		r.GuessCooldowns[playerID] = clock.Now().Add(r.Settings.guessCooldown())
+	case PenaltyElimination:
+		index := r.findPlayerById(playerID)
+		if index != -1 && r.WrongGuesses[playerID] >= r.Settings.MaxWrongGuesses {
+			r.Players[index].IsEliminated = true
+			return true
+		}
+	}
+	return false
+}
+
+// wrongGuessesLocked копия счётчиков неверных догадок. Вызывается под dataMu.
+func (r *Room) wrongGuessesLocked() map[string]int {
+	wrong := make(map[string]int, len(r.WrongGuesses))
+	for id, count := range r.WrongGuesses {
+		wrong[id] = count
+	}
+	return wrong
+}
+
+// Guess проверяет догадку игрока. Верная догадка сразу делает его
+// победителем, неверная наказывается по правилам комнаты.
+func (r *Room) Guess(playerID, playerName, guess string) {
+	r.dataMu.Lock()
+	answers := r.answersLocked(playerID)
+	match := matcherFor(r.Settings.MatchStrictness).Match(guess, answers)
+	r.GuessCounts[playerID]++
+
+	eliminated := false
+	if !match.Accepted() {
+		eliminated = r.wrongGuessLocked(playerID)
+	}
+	penalty := r.Settings.WrongGuessPenalty
+	wrong := r.WrongGuesses[playerID]
+	character := r.Characters[playerID]
+	r.dataMu.Unlock()
+
+	r.sendMessageToAll(WSGuessResultResponse{
+		Type:         "guess_result",
+		PlayerID:     playerID,
+		PlayerName:   playerName,
+		Character:    guess,
+		Correct:      match.Accepted(),
+		Match:        match,
+		WrongGuesses: wrong,
+		Text:         fmt.Sprintf("%s guessed: %s", playerName, guess),
+		Timestamp:    time.Now().Unix(),
+	})
+
+	switch {
+	case match.Accepted():
+		r.AddWinner(WSAddWinnerMessage{WinnerID: playerID})
+	case eliminated:
+		// Выбывшему раскрываем его персонажа, остальные его и так знают
+		r.sendMessageToAll(WSEliminatedResponse{
+			Type:         "eliminated",
+			PlayerID:     playerID,
+			PlayerName:   playerName,
+			Character:    character,
+			WrongGuesses: wrong,
+			Text:         fmt.Sprintf("%s is out of guesses! They were %s", playerName, character),
+			Timestamp:    time.Now().Unix(),
+		})
+		r.passTurn(playerID)
+		r.checkGameOver()
+	case penalty == PenaltyLoseTurn:
+		r.passTurn(playerID)
+	}
+}
+
+// canGuess проверка, что игрок ещё в игре и не ждёт после неверной догадки
+func canGuess[T any]() func(*CommandContext, *T) error {
+	return func(ctx *CommandContext, _ *T) error {
+		room := ctx.Room
+		room.dataMu.RLock()
+		defer room.dataMu.RUnlock()
+
+		index := room.findPlayerById(ctx.PlayerID)
+		if index == -1 {
+			return badRequest("player not found")
+		}
+		player := room.Players[index]
+		if player.IsEliminated {
+			return &CommandError{
+				Code:    ErrCodeEliminated,
+				Message: "you are out of guesses",
+			}
+		}
+		if player.IsWinner {
+			return badRequest("you have already guessed your character")
+		}
+
+		if until, exists := room.GuessCooldowns[ctx.PlayerID]; exists {
+			if wait := until.Sub(clock.Now()); wait > 0 {
+				return &CommandError{
+					Code:    ErrCodeGuessCooldown,
+					Message: fmt.Sprintf("you can guess again in %d seconds", int(wait.Seconds()+0.5)),
+				}
+			}
+		}
+		return nil
+	}
+}
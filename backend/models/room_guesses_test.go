@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+// assignCharacters раздаёт персонажей напрямую и начинает угадывание
+func assignCharacters(t *testing.T, room *Room, characters map[string]string) {
+	t.Helper()
+	startGuessing(t, room)
+	room.dataMu.Lock()
+	for name, character := range characters {
+		room.Characters[room.Players[room.findPlayerByName(name)].ID] = character
+	}
+	room.dataMu.Unlock()
+}
+
+func guess(t *testing.T, room *Room, name, character string) error {
+	t.Helper()
+	msg := fmt.Sprintf(`{"type":"guess","character":%q}`, character)
+	return room.HandleMessage(playerID(t, room, name), name, []byte(msg))
+}
+
+func TestCorrectGuessWins(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob", "carol")
+	bob := playerID(t, room, "bob")
+	assignCharacters(t, room, map[string]string{"alice": "Yoda", "bob": "Darth Vader", "carol": "Leia"})
+
+	if err := guess(t, room, "bob", "darth vader"); err != nil {
+		t.Fatal(err)
+	}
+	room.dataMu.RLock()
+	won := room.Players[room.findPlayerById(bob)].IsWinner
+	winners := room.Winners
+	phase := room.Phase
+	room.dataMu.RUnlock()
+	if !won || len(winners) != 1 || winners[0].PlayerID != bob {
+		t.Fatalf("bob won = %v, winners = %v", won, winners)
+	}
+	if phase != PhaseGuessing {
+		t.Fatalf("phase = %s with two players still guessing", phase)
+	}
+	if err := guess(t, room, "bob", "Darth Vader"); errorCode(err) != ErrCodeBadRequest {
+		t.Fatalf("guess after winning: err = %v", err)
+	}
+}
+
+func TestEliminationRevealsCharacter(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob", "carol")
+	carol := playerID(t, room, "carol")
+	setRoomSettings(t, room, `{"wrongGuessPenalty": "elimination", "maxWrongGuesses": 2}`)
+	assignCharacters(t, room, map[string]string{"alice": "Yoda", "bob": "Darth Vader", "carol": "Leia"})
+
+	for _, wrong := range []string{"Han Solo", "Chewbacca"} {
+		if err := guess(t, room, "carol", wrong); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var eliminated *WSEliminatedResponse
+	room.dataMu.RLock()
+	for _, event := range room.Events {
+		if msg, ok := event.Payload.(WSEliminatedResponse); ok {
+			eliminated = &msg
+		}
+	}
+	room.dataMu.RUnlock()
+	if eliminated == nil || eliminated.PlayerID != carol || eliminated.Character != "Leia" || eliminated.WrongGuesses != 2 {
+		t.Fatalf("eliminated = %+v, want carol revealed as Leia", eliminated)
+	}
+	if got := room.GetGameStateForPlayer(carol).Characters[carol]; got != "Leia" {
+		t.Fatalf("eliminated player sees own character %q, want Leia", got)
+	}
+	if err := guess(t, room, "carol", "Leia"); errorCode(err) != ErrCodeEliminated {
+		t.Fatalf("guess after elimination: err = %v, want %s", err, ErrCodeEliminated)
+	}
+
+	// bob отгадывает, alice остаётся одна и игра заканчивается
+	if err := guess(t, room, "bob", "Darth Vader"); err != nil {
+		t.Fatal(err)
+	}
+	if phase := roomPhase(room); phase != PhaseFinished {
+		t.Fatalf("phase = %s, want %s", phase, PhaseFinished)
+	}
+}
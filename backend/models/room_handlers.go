@@ -1,118 +1,198 @@
 package models
 
-import (
-	"encoding/json"
-	"fmt"
-	"time"
-)
+import "strings"
+
+func init() {
+	registerRoute("ping", nil, handlePing)
+	registerRoute("chat", validateChat, handleChat)
+	registerRoute("question", validators(inPhase[WSQuestionMessage](PhaseGuessing), notSpectator[WSQuestionMessage](), notPaused[WSQuestionMessage](), onTurn[WSQuestionMessage](), validateQuestion), handleQuestion)
+	registerRoute("answer", validators(inPhase[WSAnswerMessage](PhaseGuessing), notSpectator[WSAnswerMessage](), notPaused[WSAnswerMessage](), validateAnswer), handleAnswer)
+	registerRoute("set_character", validators(inPhase[WSSetCharacterMessage](PhaseAssigning), notPaused[WSSetCharacterMessage](), validateSetCharacter), handleSetCharacter)
+	registerRoute("suggest_character", validators(inPhase[WSSuggestCharacterMessage](PhaseAssigning), notPaused[WSSuggestCharacterMessage]()), handleSuggestCharacter)
+	registerRoute("guess", validators(inPhase[WSGuessMessage](PhaseGuessing), notSpectator[WSGuessMessage](), notPaused[WSGuessMessage](), canGuess[WSGuessMessage](), onTurn[WSGuessMessage](), validateGuess), handleGuess)
+	registerRoute("add_winner", validators(hostOnly[WSAddWinnerMessage](ActionAddWinner), inPhase[WSAddWinnerMessage](PhaseGuessing), validateAddWinner), handleAddWinner)
+	registerRoute("end_game", validators(hostOnly[WSEndGameMessage](ActionEndGame), inPhase[WSEndGameMessage](PhaseAssigning, PhaseGuessing)), handleEndGame)
+	registerRoute("pause", validators(hostOnly[WSPauseMessage](ActionPause), inPhase[WSPauseMessage](PhaseAssigning, PhaseGuessing)), handlePause)
+	registerRoute("resume", validators(hostOnly[WSResumeMessage](ActionResume), inPhase[WSResumeMessage](PhaseAssigning, PhaseGuessing)), handleResume)
+	registerRoute("rematch", validators(hostOnly[WSRematchMessage](ActionRematch), inPhase[WSRematchMessage](PhaseFinished)), handleRematch)
+	registerRoute("update_settings", validators(hostOnly[WSUpdateSettingsMessage](ActionSettings), inPhase[WSUpdateSettingsMessage](PhaseLobby), validateUpdateSettings), handleUpdateSettings)
+	// Удаление пересчитывает ход, цепочку загадывания и конец игры, а
+	// перестановка меняет WhoMakeFor, поэтому она только в лобби
+	registerRoute("remove_player", validators(hostOnly[WSRemovePlayerMessage](ActionRemovePlayer), validateRemovePlayer), handleRemovePlayer)
+	registerRoute("move_player", validators(hostOnly[WSMovePlayerMessage](ActionMovePlayer), inPhase[WSMovePlayerMessage](PhaseLobby), validateMovePlayer), handleMovePlayer)
+}
 
-func handleWSMessage(room *Room, msgBytes []byte, playerID, playerName string) error {
-	var baseMsg WSMessageBase
-	if err := json.Unmarshal(msgBytes, &baseMsg); err != nil {
-		return fmt.Errorf("error parsing message type: %w", err)
+func handlePing(ctx *CommandContext, _ *WSPingMessage) error {
+	ctx.Room.SendToPlayer(ctx.PlayerID, WSPongResponse{
+		Type: "pong",
+	})
+	return nil
+}
+
+func validateChat(_ *CommandContext, msg *WSChatMessage) error {
+	if strings.TrimSpace(msg.Text) == "" {
+		return badRequest("chat text is required")
 	}
+	return nil
+}
 
-	timestamp := time.Now().Unix()
+func handleChat(ctx *CommandContext, msg *WSChatMessage) error {
+	ctx.Room.Broadcast(WSChatResponse{
+		Type:       "chat",
+		PlayerID:   ctx.PlayerID,
+		PlayerName: ctx.PlayerName,
+		Text:       msg.Text,
+		Timestamp:  ctx.Timestamp,
+	})
+	return nil
+}
 
-	switch baseMsg.Type {
-	case "chat":
-		var msg WSChatMessage
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			return fmt.Errorf("error parsing chat: %w", err)
-		}
-		room.Broadcast(WSChatResponse{
-			Type:       "chat",
-			PlayerID:   playerID,
-			PlayerName: playerName,
-			Text:       msg.Text,
-			Timestamp:  timestamp,
-		})
-
-	case "question":
-		var msg WSQuestionMessage
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			return fmt.Errorf("error parsing question: %w", err)
-		}
-		room.Broadcast(WSQuestionResponse{
-			Type:       "question",
-			PlayerID:   playerID,
-			PlayerName: playerName,
-			Text:       msg.Text,
-			Timestamp:  timestamp,
-		})
-
-	case "answer":
-		var msg WSQuestionMessage
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			return fmt.Errorf("error parsing answer: %w", err)
-		}
-		room.Broadcast(WSAnswerResponse{
-			Type:       "answer",
-			PlayerID:   playerID,
-			PlayerName: playerName,
-			Text:       msg.Text,
-			Timestamp:  timestamp,
-		})
-
-	case "set_character":
-		var msg WSSetCharacterMessage
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			return fmt.Errorf("error parsing set_character: %w", err)
-		}
-		msg.PlayerID = playerID
-		room.SetCharacter(msg)
+func validateQuestion(_ *CommandContext, msg *WSQuestionMessage) error {
+	if strings.TrimSpace(msg.Text) == "" {
+		return badRequest("text is required")
+	}
+	return nil
+}
 
-	case "add_winner":
-		var msg WSAddWinnerMessage
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			return fmt.Errorf("error parsing add_winner: %w", err)
-		}
-		room.AddWinner(msg)
+func handleQuestion(ctx *CommandContext, msg *WSQuestionMessage) error {
+	ctx.Room.AskQuestion(ctx.PlayerID, ctx.PlayerName, msg.Text, ctx.Timestamp)
+	return nil
+}
 
-	case "remove_player":
-		var msg WSRemovePlayerMessage
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			return fmt.Errorf("error parsing remove_player: %w", err)
-		}
-		room.RemovePlayerWithNotification(msg.RemovedID)
+func validateAnswer(_ *CommandContext, msg *WSAnswerMessage) error {
+	if msg.Answer == "" {
+		msg.Answer = parseAnswerValue(msg.Text)
+	}
+	if !msg.Answer.Valid() {
+		return badRequest("answer must be one of yes, no, maybe, irrelevant")
+	}
+	return nil
+}
 
-	case "move_player":
-		var msg WSMovePlayerMessage
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			return fmt.Errorf("error parsing move_player: %w", err)
-		}
-		room.MovePlayer(msg.PlayerName, msg.Index)
+func handleAnswer(ctx *CommandContext, msg *WSAnswerMessage) error {
+	room := ctx.Room
+	question, err := room.AnswerQuestion(ctx.PlayerID, ctx.PlayerName, msg.QuestionID, msg.Answer, msg.Text)
+	if err != nil {
+		return err
+	}
 
-	case "ping":
-		room.SendToPlayer(playerID, WSPongResponse{
-			Type:      "pong",
-		})
+	// В режиме ходов «нет» на вопрос активного игрока передаёт ход.
+	// При голосовании это решает итог, см. closeVote.
+	if msg.Answer == AnswerNo && question.Vote == nil {
+		room.passTurnOnQuestion(question.ID)
+	}
+	return nil
+}
 
-	case "guess":
-		var msg WSGuessMessage
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
-			return fmt.Errorf("error parsing guess: %w", err)
+func validateSetCharacter(_ *CommandContext, msg *WSSetCharacterMessage) error {
+	if strings.TrimSpace(msg.Character) == "" {
+		return badRequest("character is required")
+	}
+	if len(msg.Aliases) > maxCharacterAliases {
+		return badRequest("at most %d aliases are allowed", maxCharacterAliases)
+	}
+	for _, alias := range msg.Aliases {
+		if len([]rune(alias)) > maxAliasLength {
+			return badRequest("alias is longer than %d characters", maxAliasLength)
 		}
+	}
+	if len([]rune(msg.Description)) > maxDescriptionLength {
+		return badRequest("description is longer than %d characters", maxDescriptionLength)
+	}
+	return nil
+}
 
-		room.dataMu.RLock()
-		correctCharacter := room.Characters[playerID]
-		room.dataMu.RUnlock()
+func handleSetCharacter(ctx *CommandContext, msg *WSSetCharacterMessage) error {
+	// Персонажа задаёт только сам игрок, ID из сообщения не доверяем
+	msg.PlayerID = ctx.PlayerID
+	return ctx.Room.SetCharacter(*msg)
+}
 
-		isCorrect := msg.Character == correctCharacter
+func handleSuggestCharacter(ctx *CommandContext, msg *WSSuggestCharacterMessage) error {
+	filter := DeckFilter{
+		Category:   msg.Category,
+		Difficulty: msg.Difficulty,
+	}
+	return ctx.Room.SuggestCharacters(ctx.PlayerID, msg.Count, filter)
+}
 
-		room.Broadcast(WSGuessResultResponse{
-			Type:       "guess_result",
-			PlayerID:   playerID,
-			PlayerName: playerName,
-			Character:  msg.Character,
-			Correct:    isCorrect,
-			Text:       fmt.Sprintf("%s guessed: %s", playerName, msg.Character),
-			Timestamp:  timestamp,
-		})
+func validateGuess(_ *CommandContext, msg *WSGuessMessage) error {
+	if strings.TrimSpace(msg.Character) == "" {
+		return badRequest("character is required")
+	}
+	return nil
+}
+
+func handleGuess(ctx *CommandContext, msg *WSGuessMessage) error {
+	ctx.Room.Guess(ctx.PlayerID, ctx.PlayerName, msg.Character)
+	return nil
+}
 
-	default:
-		return fmt.Errorf("unknown message type: %s", baseMsg.Type)
+func validateAddWinner(_ *CommandContext, msg *WSAddWinnerMessage) error {
+	if msg.WinnerID == "" {
+		return badRequest("winnerId is required")
 	}
+	return nil
+}
+
+func handleAddWinner(ctx *CommandContext, msg *WSAddWinnerMessage) error {
+	ctx.Room.AddWinner(*msg)
+	return nil
+}
+
+func handleEndGame(ctx *CommandContext, _ *WSEndGameMessage) error {
+	return ctx.Room.EndGame(GameOverHostEnded)
+}
+
+func handlePause(ctx *CommandContext, _ *WSPauseMessage) error {
+	return ctx.Room.Pause(ctx.PlayerID)
+}
+
+func handleResume(ctx *CommandContext, _ *WSResumeMessage) error {
+	return ctx.Room.Resume(ctx.PlayerID)
+}
+
+func handleRematch(ctx *CommandContext, msg *WSRematchMessage) error {
+	return ctx.Room.Rematch(msg.Shuffle)
+}
+
+func validateUpdateSettings(_ *CommandContext, msg *WSUpdateSettingsMessage) error {
+	if len(msg.Settings) == 0 {
+		return badRequest("settings are required")
+	}
+	return nil
+}
+
+func handleUpdateSettings(ctx *CommandContext, msg *WSUpdateSettingsMessage) error {
+	_, err := ctx.Room.UpdateSettings(msg.Settings)
+	return err
+}
+
+func validateRemovePlayer(_ *CommandContext, msg *WSRemovePlayerMessage) error {
+	if msg.RemovedID == "" {
+		return badRequest("removedId is required")
+	}
+	return nil
+}
+
+func handleRemovePlayer(ctx *CommandContext, msg *WSRemovePlayerMessage) error {
+	if !ctx.Room.RemovePlayerWithNotification(msg.RemovedID) {
+		return badRequest("player %s not found", msg.RemovedID)
+	}
+	return nil
+}
+
+func validateMovePlayer(_ *CommandContext, msg *WSMovePlayerMessage) error {
+	if msg.PlayerID == "" && msg.PlayerName == "" {
+		return badRequest("playerId is required")
+	}
+	if msg.Index < 0 {
+		return badRequest("index must not be negative")
+	}
+	return nil
+}
 
+func handleMovePlayer(ctx *CommandContext, msg *WSMovePlayerMessage) error {
+	ctx.Room.MovePlayer(*msg)
 	return nil
 }
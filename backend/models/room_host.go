@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// HostGracePeriod сколько ждём переподключения хоста, прежде чем передать права
+var HostGracePeriod = 30 * time.Second
+
+func (r *Room) isConnected(playerID string) bool {
+	r.connMu.RLock()
+	defer r.connMu.RUnlock()
+	_, exists := r.Connections[playerID]
+	return exists
+}
+
+func (r *Room) connectedIDs() map[string]bool {
+	r.connMu.RLock()
+	defer r.connMu.RUnlock()
+
+	ids := make(map[string]bool, len(r.Connections))
+	for id := range r.Connections {
+		ids[id] = true
+	}
+	return ids
+}
+
+// scheduleHostMigration передаёт права хоста, если он не вернулся за HostGracePeriod
+func (r *Room) scheduleHostMigration(hostID string) {
+	clock.AfterFunc(HostGracePeriod, func() {
+		if !r.IsHost(hostID) || r.isConnected(hostID) {
+			return
+		}
+
+		r.dataMu.RLock()
+		index := r.findPlayerById(hostID)
+		r.dataMu.RUnlock()
+
+		r.transferHost(hostID, index+1)
+	})
+}
+
+// transferHost назначает хостом следующего игрока в Players, начиная с позиции
+// start. Подключённые игроки в приоритете. Если сменить хоста не на кого,
+// комната остаётся без хоста до прихода нового игрока.
+func (r *Room) transferHost(fromID string, start int) {
+	connected := r.connectedIDs()
+
+	r.dataMu.Lock()
+	if r.HostID != fromID {
+		r.dataMu.Unlock()
+		return
+	}
+
+	var next *Player
+	for i := range r.Players {
+		candidate := &r.Players[(start+i)%len(r.Players)]
+		if candidate.ID == fromID {
+			continue
+		}
+		if connected[candidate.ID] {
+			next = candidate
+			break
+		}
+		if next == nil {
+			next = candidate
+		}
+	}
+
+	if next == nil {
+		r.HostID = ""
+		r.dataMu.Unlock()
+		r.persist()
+		return
+	}
+
+	r.HostID = next.ID
+	hostName := next.Name
+	r.dataMu.Unlock()
+
+	r.sendMessageToAll(WSHostChangedResponse{
+		Type:      "host_changed",
+		HostID:    next.ID,
+		HostName:  hostName,
+		Text:      hostName + " is now the host",
+		Timestamp: time.Now().Unix(),
+	})
+}
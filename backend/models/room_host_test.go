@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func roomHost(room *Room) string {
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+	return room.HostID
+}
+
+// hostChanges сообщения host_changed из журнала комнаты
+func hostChanges(room *Room) []WSHostChangedResponse {
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+
+	var changes []WSHostChangedResponse
+	for _, event := range room.Events {
+		if msg, ok := event.Payload.(WSHostChangedResponse); ok {
+			changes = append(changes, msg)
+		}
+	}
+	return changes
+}
+
+func TestHostMigratesAfterGracePeriod(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob", "carol")
+	alice, carol := playerID(t, room, "alice"), playerID(t, room, "carol")
+
+	// bob не в сети, права достаются подключённой carol
+	host := connectFake(t, room, alice)
+	connectFake(t, room, carol)
+
+	room.ConnectionLost(host)
+	c.Advance(HostGracePeriod - time.Second)
+	if got := roomHost(room); got != alice {
+		t.Fatalf("host changed to %s before the grace period ended", got)
+	}
+
+	c.Advance(time.Second)
+	if got := roomHost(room); got != carol {
+		t.Fatalf("host = %s, want carol", got)
+	}
+	changes := hostChanges(room)
+	if len(changes) != 1 || changes[0].HostID != carol {
+		t.Fatalf("host_changed = %+v, want one for carol", changes)
+	}
+}
+
+func TestHostKeepsRightsAfterReconnect(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob")
+	alice := playerID(t, room, "alice")
+	connectFake(t, room, playerID(t, room, "bob"))
+
+	room.ConnectionLost(connectFake(t, room, alice))
+	c.Advance(HostGracePeriod / 2)
+	connectFake(t, room, alice)
+	c.Advance(HostGracePeriod)
+
+	if got := roomHost(room); got != alice {
+		t.Fatalf("host = %s, want alice after the reconnect", got)
+	}
+	if changes := hostChanges(room); len(changes) != 0 {
+		t.Fatalf("unexpected host_changed: %+v", changes)
+	}
+}
+
+func TestRemovedHostPassesRights(t *testing.T) {
+	useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob", "carol")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+	connectFake(t, room, alice)
+	connectFake(t, room, bob)
+
+	if !room.RemovePlayerWithNotification(alice) {
+		t.Fatal("alice was not removed")
+	}
+	if got := roomHost(room); got != bob {
+		t.Fatalf("host = %s, want bob", got)
+	}
+	changes := hostChanges(room)
+	if len(changes) != 1 || changes[0].HostID != bob || changes[0].HostName != "bob" {
+		t.Fatalf("host_changed = %+v, want one for bob", changes)
+	}
+}
@@ -3,20 +3,7 @@ package models
 import "log"
 
 func (r *Room) sendMessageToAll(msg interface{}) {
-	r.dataMu.Lock()
-	r.Messages = append(r.Messages, msg)
-	r.dataMu.Unlock()
-
-	r.connMu.RLock()
-	defer r.connMu.RUnlock()
-
-	for _, pc := range r.Connections {
-		select {
-		case pc.send <- msg:
-		default:
-			log.Printf("Channel full for player %s", pc.player.ID)
-		}
-	}
+	r.sendMessageToAllWithExceptions(msg, nil)
 }
 
 func (r *Room) sendMessageToAllWithExceptions(msg interface{}, exceptions []string) {
@@ -25,24 +12,41 @@ func (r *Room) sendMessageToAllWithExceptions(msg interface{}, exceptions []stri
 		exceptMap[id] = true
 	}
 
-	r.dataMu.Lock()
-	r.Messages = append(r.Messages, msg)
-	r.dataMu.Unlock()
+	// eventMu держим до конца рассылки, чтобы клиенты получали события
+	// в порядке их номеров
+	r.eventMu.Lock()
+	event := r.appendEvent(msg, exceptions)
 
 	r.connMu.RLock()
-	defer r.connMu.RUnlock()
-
 	for playerID, pc := range r.Connections {
 		if exceptMap[playerID] {
 			continue
 		}
 
 		select {
-		case pc.send <- msg:
+		case pc.send <- event:
 		default:
 			log.Printf("Channel full for player %s", playerID)
 		}
 	}
+	r.connMu.RUnlock()
+	r.eventMu.Unlock()
+
+	r.persist()
+}
+
+// sendTransientToAll рассылает сообщение, не сохраняя его в историю комнаты
+func (r *Room) sendTransientToAll(msg interface{}) {
+	r.connMu.RLock()
+	defer r.connMu.RUnlock()
+
+	for _, pc := range r.Connections {
+		select {
+		case pc.send <- msg:
+		default:
+			log.Printf("Channel full for player %s", pc.player.ID)
+		}
+	}
 }
 
 func (r *Room) Broadcast(msg interface{}) {
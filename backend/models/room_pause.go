@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const ErrCodePaused = "paused"
+
+// Pause замораживает игру: таймеры останавливаются, оставшееся время
+// сохраняется в дедлайнах и возвращается при Resume
+func (r *Room) Pause(playerID string) error {
+	r.dataMu.Lock()
+	if r.Paused {
+		r.dataMu.Unlock()
+		return badRequest("game is already paused")
+	}
+	r.Paused = true
+	r.PausedAt = clock.Now()
+	r.haltTimersLocked()
+	pausedAt := r.PausedAt
+	r.dataMu.Unlock()
+
+	r.broadcastPause(playerID, true, pausedAt)
+	return nil
+}
+
+// Resume продолжает игру, сдвигая дедлайны на длительность паузы
+func (r *Room) Resume(playerID string) error {
+	r.dataMu.Lock()
+	if !r.Paused {
+		r.dataMu.Unlock()
+		return badRequest("game is not paused")
+	}
+
+	pause := clock.Now().Sub(r.PausedAt)
+	r.Paused = false
+	r.PausedAt = time.Time{}
+	if !r.TurnDeadline.IsZero() {
+		r.TurnDeadline = r.TurnDeadline.Add(pause)
+	}
+	if !r.GameDeadline.IsZero() {
+		r.GameDeadline = r.GameDeadline.Add(pause)
+	}
+	if !r.GuessingStartedAt.IsZero() {
+		r.GuessingStartedAt = r.GuessingStartedAt.Add(pause)
+	}
+	for id, until := range r.GuessCooldowns {
+		r.GuessCooldowns[id] = until.Add(pause)
+	}
+	for i := range r.Questions {
+		if vote := r.Questions[i].Vote; vote != nil && !vote.Closed {
+			vote.Deadline = vote.Deadline.Add(pause)
+			r.scheduleVoteLocked(r.Questions[i])
+		}
+	}
+	r.resumeTimersLocked()
+	r.dataMu.Unlock()
+
+	r.broadcastPause(playerID, false, time.Time{})
+	return nil
+}
+
+// clearPauseLocked снимает паузу при выходе из игры. Вызывается под dataMu.
+func (r *Room) clearPauseLocked() {
+	r.Paused = false
+	r.PausedAt = time.Time{}
+}
+
+// haltTimersLocked останавливает таймеры, не трогая дедлайны.
+// Вызывается под dataMu.
+func (r *Room) haltTimersLocked() {
+	for _, timer := range []Timer{r.turnTimer, r.gameTimer, r.tickTimer} {
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	r.turnTimer, r.gameTimer, r.tickTimer = nil, nil, nil
+	r.stopVoteTimersLocked()
+}
+
+func (r *Room) broadcastPause(playerID string, paused bool, pausedAt time.Time) {
+	name := playerID
+	if player := r.GetPlayer(playerID); player != nil {
+		name = player.Name
+	}
+
+	msg := WSPauseResponse{
+		Type:      "resumed",
+		Paused:    paused,
+		PlayerID:  playerID,
+		Text:      fmt.Sprintf("%s resumed the game", name),
+		Timestamp: clock.Now().Unix(),
+	}
+	if paused {
+		msg.Type = "paused"
+		msg.PausedAt = pausedAt.Unix()
+		msg.Text = fmt.Sprintf("%s paused the game", name)
+	}
+	r.sendMessageToAll(msg)
+}
+
+// notPaused проверка для игровых команд, недоступных на паузе
+func notPaused[T any]() func(*CommandContext, *T) error {
+	return func(ctx *CommandContext, _ *T) error {
+		room := ctx.Room
+		room.dataMu.RLock()
+		defer room.dataMu.RUnlock()
+
+		if room.Paused {
+			return &CommandError{
+				Code:    ErrCodePaused,
+				Message: "the game is paused",
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type Action string
+
+const (
+	ActionStartGame    Action = "start_game"
+	ActionRemovePlayer Action = "remove_player"
+	ActionMovePlayer   Action = "move_player"
+	ActionAddWinner    Action = "add_winner"
+	ActionEndGame      Action = "end_game"
+	ActionRematch      Action = "rematch"
+	ActionPause        Action = "pause"
+	ActionResume       Action = "resume"
+	ActionSettings     Action = "update_settings"
+	ActionInvite       Action = "invite"
+)
+
+// Действия, доступные только хосту комнаты
+var hostOnlyActions = map[Action]bool{
+	ActionStartGame:    true,
+	ActionRemovePlayer: true,
+	ActionMovePlayer:   true,
+	ActionAddWinner:    true,
+	ActionEndGame:      true,
+	ActionRematch:      true,
+	ActionPause:        true,
+	ActionResume:       true,
+	ActionSettings:     true,
+	ActionInvite:       true,
+}
+
+const ErrCodeForbidden = "forbidden"
+
+// CommandError ошибка команды с машиночитаемым кодом для клиента
+type CommandError struct {
+	Code    string
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	return e.Message
+}
+
+func (r *Room) IsHost(playerID string) bool {
+	r.dataMu.RLock()
+	defer r.dataMu.RUnlock()
+	return playerID != "" && r.HostID == playerID
+}
+
+// Authorize проверяет, что игрок может выполнить действие
+func (r *Room) Authorize(playerID string, action Action) error {
+	if hostOnlyActions[action] && !r.IsHost(playerID) {
+		return &CommandError{
+			Code:    ErrCodeForbidden,
+			Message: "only the host can " + string(action),
+		}
+	}
+	return nil
+}
+
+// NewErrorResponse превращает ошибку обработки в сообщение для клиента
+func NewErrorResponse(err error) WSErrorResponse {
+	resp := WSErrorResponse{
+		Type:      "error",
+		Error:     err.Error(),
+		Timestamp: time.Now().Unix(),
+	}
+
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		resp.Code = cmdErr.Code
+	}
+	return resp
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHostOnlyActions(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+
+	if err := room.Authorize(bob, ActionStartGame); errorCode(err) != ErrCodeForbidden {
+		t.Fatalf("non-host start: err = %v, want forbidden", err)
+	}
+	move := fmt.Sprintf(`{"type":"move_player","playerId":%q,"index":0}`, bob)
+	if err := room.HandleMessage(bob, "bob", []byte(move)); errorCode(err) != ErrCodeForbidden {
+		t.Fatalf("non-host move: err = %v, want forbidden", err)
+	}
+	remove := fmt.Sprintf(`{"type":"remove_player","removedId":%q}`, alice)
+	if err := room.HandleMessage(bob, "bob", []byte(remove)); errorCode(err) != ErrCodeForbidden {
+		t.Fatalf("non-host remove: err = %v, want forbidden", err)
+	}
+
+	if err := room.Authorize(alice, ActionStartGame); err != nil {
+		t.Fatalf("host start: %v", err)
+	}
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	if phase := roomPhase(room); phase != PhaseAssigning {
+		t.Fatalf("phase = %s, want %s", phase, PhaseAssigning)
+	}
+}
+
+func TestMovePlayerOnlyInLobby(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob", "carol")
+	alice, carol := playerID(t, room, "alice"), playerID(t, room, "carol")
+	move := []byte(fmt.Sprintf(`{"type":"move_player","playerId":%q,"index":0}`, carol))
+
+	if err := room.HandleMessage(alice, "alice", move); err != nil {
+		t.Fatalf("move in lobby: %v", err)
+	}
+	room.dataMu.RLock()
+	first := room.Players[0].ID
+	room.dataMu.RUnlock()
+	if first != carol {
+		t.Fatalf("first player = %s, want carol", first)
+	}
+
+	startTurnMode(t, room, alice)
+	if err := room.HandleMessage(alice, "alice", move); errorCode(err) != ErrCodeWrongPhase {
+		t.Fatalf("move during the game: err = %v, want %s", err, ErrCodeWrongPhase)
+	}
+}
+
+func TestRemoveActivePlayerPassesTurn(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob", "carol")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+	startTurnMode(t, room, bob)
+
+	remove := []byte(fmt.Sprintf(`{"type":"remove_player","removedId":%q}`, bob))
+	if err := room.HandleMessage(alice, "alice", remove); err != nil {
+		t.Fatal(err)
+	}
+	if got := activePlayer(room); got != playerID(t, room, "carol") {
+		t.Fatalf("active = %s, want carol after bob was removed", got)
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type GamePhase string
+
+const (
+	PhaseLobby     GamePhase = "lobby"
+	PhaseAssigning GamePhase = "assigning"
+	PhaseGuessing  GamePhase = "guessing"
+	PhaseFinished  GamePhase = "finished"
+)
+
+const ErrCodeWrongPhase = "wrong_phase"
+
+// Допустимые переходы между фазами игры
+var phaseTransitions = map[GamePhase][]GamePhase{
+	PhaseLobby:     {PhaseAssigning},
+	PhaseAssigning: {PhaseGuessing, PhaseFinished},
+	PhaseGuessing:  {PhaseFinished},
+	PhaseFinished:  {PhaseLobby},
+}
+
+func (p GamePhase) canMoveTo(next GamePhase) bool {
+	for _, allowed := range phaseTransitions[p] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Room) CurrentPhase() GamePhase {
+	r.dataMu.RLock()
+	defer r.dataMu.RUnlock()
+	return r.Phase
+}
+
+// setPhaseLocked переводит комнату в новую фазу, вызывается под dataMu.
+// Событие о смене фазы рассылает broadcastPhase после снятия блокировки.
+func (r *Room) setPhaseLocked(next GamePhase) (GamePhase, error) {
+	prev := r.Phase
+	if !prev.canMoveTo(next) {
+		return prev, &CommandError{
+			Code:    ErrCodeWrongPhase,
+			Message: fmt.Sprintf("cannot move from %s to %s", prev, next),
+		}
+	}
+
+	r.Phase = next
+	r.Started = next != PhaseLobby
+	if next != PhaseGuessing {
+		r.ActivePlayerID = ""
+		r.stopTimersLocked()
+	}
+	if next == PhaseFinished || next == PhaseLobby {
+		r.clearPauseLocked()
+	}
+	if next == PhaseGuessing {
+		r.GuessingStartedAt = clock.Now()
+		r.startGameTimerLocked()
+	}
+	return prev, nil
+}
+
+func (r *Room) broadcastPhase(prev, next GamePhase) {
+	r.sendMessageToAll(WSPhaseChangedResponse{
+		Type:      "phase_changed",
+		Phase:     next,
+		Previous:  prev,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// allCharactersAssignedLocked каждый, кому кто-то загадывает, уже получил персонажа
+func (r *Room) allCharactersAssignedLocked() bool {
+	if len(r.WhoMakeFor) == 0 {
+		return false
+	}
+	for _, target := range r.WhoMakeFor {
+		if r.Characters[target.ID] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// advanceIfAssigned переводит игру к угадыванию, как только все персонажи заданы
+func (r *Room) advanceIfAssigned() {
+	r.dataMu.Lock()
+	if r.Phase != PhaseAssigning || !r.allCharactersAssignedLocked() {
+		r.dataMu.Unlock()
+		return
+	}
+	prev, err := r.setPhaseLocked(PhaseGuessing)
+	r.dataMu.Unlock()
+
+	if err == nil {
+		r.broadcastPhase(prev, PhaseGuessing)
+		r.startTurns()
+	}
+}
+
+// inPhase проверка для команд, допустимых только в указанных фазах
+func inPhase[T any](phases ...GamePhase) func(*CommandContext, *T) error {
+	return func(ctx *CommandContext, _ *T) error {
+		current := ctx.Room.CurrentPhase()
+		for _, phase := range phases {
+			if current == phase {
+				return nil
+			}
+		}
+		return &CommandError{
+			Code:    ErrCodeWrongPhase,
+			Message: fmt.Sprintf("not allowed during %s phase", current),
+		}
+	}
+}
@@ -1,17 +1,31 @@
 package models
 
+const ErrCodeRoomFull = "room_full"
+
+// GetPlayer копия игрока, nil если его нет в комнате. Указатель в
+// r.Players нельзя отдавать наружу: слайс меняется при перестановках.
 func (r *Room) GetPlayer(playerID string) *Player {
 	r.dataMu.RLock()
 	defer r.dataMu.RUnlock()
 
 	for i := range r.Players {
 		if r.Players[i].ID == playerID {
-			return &r.Players[i]
+			player := r.Players[i]
+			return &player
 		}
 	}
 	return nil
 }
 
+func (r *Room) findPlayerByName(name string) int {
+	for i, player := range r.Players {
+		if player.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
 func (r *Room) findPlayerById(playerId string) int {
 	for i, player := range r.Players {
 		if player.ID == playerId {
@@ -21,33 +35,83 @@ func (r *Room) findPlayerById(playerId string) int {
 	return -1
 }
 
-func (r *Room) AddPlayer(name string) *Player {
-	r.dataMu.Lock()
-	defer r.dataMu.Unlock()
-
+// playerCountLocked сколько в комнате игроков без зрителей.
+// Вызывается под dataMu.
+func (r *Room) playerCountLocked() int {
+	count := 0
 	for _, player := range r.Players {
-		if player.Name == name {
-			return nil
+		if !player.IsSpectator {
+			count++
 		}
 	}
+	return count
+}
+
+// PlayerCount сколько в комнате игроков без зрителей
+func (r *Room) PlayerCount() int {
+	r.dataMu.RLock()
+	defer r.dataMu.RUnlock()
+	return r.playerCountLocked()
+}
+
+// AddPlayer добавляет игрока или зрителя. Зрители не учитываются в
+// maxPlayers и допускаются, только если это разрешено настройками.
+// Игроки входят только в лобби: опоздавшему некому загадать персонажа,
+// и он сбил бы порядок ходов. Зрители входят в любой фазе.
+func (r *Room) AddPlayer(name string, spectator bool) (*Player, error) {
+	r.dataMu.Lock()
+
+	if !spectator && r.Phase != PhaseLobby {
+		r.dataMu.Unlock()
+		return nil, &CommandError{Code: ErrCodeWrongPhase, Message: "the game has already started, join as a spectator"}
+	}
+
+	if r.findPlayerByName(name) != -1 {
+		r.dataMu.Unlock()
+		return nil, badRequest("there is already a user named %s", name)
+	}
+	if spectator && !r.Settings.AllowSpectators {
+		r.dataMu.Unlock()
+		return nil, &CommandError{Code: ErrCodeForbidden, Message: "spectators are not allowed in this room"}
+	}
+	if limit := r.Settings.MaxPlayers; !spectator && limit > 0 && r.playerCountLocked() >= limit {
+		r.dataMu.Unlock()
+		return nil, &CommandError{Code: ErrCodeRoomFull, Message: "room is full"}
+	}
 
 	player := Player{
-		ID:       name,
-		Name:     name,
-		IsWinner: false,
+		ID:          newPlayerID(),
+		Name:        name,
+		IsWinner:    false,
+		IsSpectator: spectator,
 	}
 
 	r.Players = append(r.Players, player)
-	return &player
+	r.SessionNonces[player.ID] = newSessionNonce()
+	// Создатель становится хостом. Если хоста не осталось, см. transferHost,
+	// права получает следующий вошедший.
+	if r.HostID == "" {
+		r.HostID = player.ID
+	}
+	r.dataMu.Unlock()
+
+	r.persist()
+	return &player, nil
 }
 
-func (r *Room) MovePlayer(playerName string, index int) {
+// MovePlayer переставляет игрока на позицию index. Старые клиенты
+// передают только имя, поэтому оно используется, если ID пустой.
+func (r *Room) MovePlayer(msg WSMovePlayerMessage) {
 	r.dataMu.Lock()
-	defer r.dataMu.Unlock()
 
-	playerIndex := r.findPlayerById(playerName)
+	playerIndex := r.findPlayerById(msg.PlayerID)
+	if playerIndex == -1 {
+		playerIndex = r.findPlayerByName(msg.PlayerName)
+	}
+	index := msg.Index
 
-	if playerIndex == index {
+	if playerIndex == -1 || index < 0 || index >= len(r.Players) || playerIndex == index {
+		r.dataMu.Unlock()
 		return
 	}
 
@@ -62,4 +126,24 @@ func (r *Room) MovePlayer(playerName string, index int) {
 	}
 
 	r.calcWhoMakeFor()
+	r.dataMu.Unlock()
+
+	r.persist()
+}
+
+// notSpectator зрители только наблюдают и пишут в чат
+func notSpectator[T any]() func(*CommandContext, *T) error {
+	return func(ctx *CommandContext, _ *T) error {
+		room := ctx.Room
+		room.dataMu.RLock()
+		defer room.dataMu.RUnlock()
+
+		if index := room.findPlayerById(ctx.PlayerID); index != -1 && room.Players[index].IsSpectator {
+			return &CommandError{
+				Code:    ErrCodeForbidden,
+				Message: "spectators can't play",
+			}
+		}
+		return nil
+	}
 }
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestAddPlayerOnlyInLobby(t *testing.T) {
+	for _, phase := range []GamePhase{PhaseAssigning, PhaseGuessing, PhaseFinished} {
+		t.Run(string(phase), func(t *testing.T) {
+			room := newTestRoom(t, "alice", "bob")
+			room.dataMu.Lock()
+			room.Settings.AllowSpectators = true
+			room.Phase = phase
+			room.dataMu.Unlock()
+
+			if _, err := room.AddPlayer("carol", false); errorCode(err) != ErrCodeWrongPhase {
+				t.Fatalf("AddPlayer() error = %v, want %s", err, ErrCodeWrongPhase)
+			}
+			if count := room.PlayerCount(); count != 2 {
+				t.Fatalf("PlayerCount() = %d after a rejected join", count)
+			}
+
+			// Зрители входят в любой фазе
+			if _, err := room.AddPlayer("dave", true); err != nil {
+				t.Fatalf("spectator join: %v", err)
+			}
+		})
+	}
+}
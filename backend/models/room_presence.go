@@ -0,0 +1,150 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type PresenceState string
+
+const (
+	PresenceOnline       PresenceState = "online"
+	PresenceReconnecting PresenceState = "reconnecting"
+	PresenceOffline      PresenceState = "offline"
+)
+
+var reconnectGracePeriod = 20 * time.Second
+
+// SetReconnectGracePeriod задаёт, сколько ждём переподключения игрока,
+// прежде чем объявить, что он вышел
+func SetReconnectGracePeriod(d time.Duration) {
+	reconnectGracePeriod = d
+}
+
+// AddConnection подключает сокет игрока, отправляет ему init и события
+// после since и запускает запись в сокет. Если игрок переподключился
+// в пределах окна ожидания (или старый сокет ещё не успел закрыться),
+// старое соединение молча заменяется и reconnected = true. Если игрока
+// успели удалить из комнаты, возвращается ошибка и сокет нужно закрыть.
+func (r *Room) AddConnection(playerID string, conn *websocket.Conn, since int64) (pc *PlayerConnection, reconnected bool, err error) {
+	player := r.GetPlayer(playerID)
+	if player == nil {
+		return nil, false, fmt.Errorf("player %s is not in room %s", playerID, r.Code)
+	}
+
+	// Пока держим eventMu, новых событий нет: всё до LastSeq уйдёт
+	// досылкой, всё после придёт обычной рассылкой, без повторов
+	r.eventMu.Lock()
+	r.connMu.Lock()
+	pc = NewPlayerConnection(conn, player, r)
+
+	if old, exists := r.Connections[playerID]; exists {
+		close(old.send)
+		reconnected = true
+	}
+	if r.Presence[playerID] == PresenceReconnecting {
+		reconnected = true
+	}
+	if timer, exists := r.graceTimers[playerID]; exists {
+		timer.Stop()
+		delete(r.graceTimers, playerID)
+	}
+
+	r.Connections[playerID] = pc
+	r.Presence[playerID] = PresenceOnline
+	r.connMu.Unlock()
+
+	state := r.GetGameStateForPlayer(playerID)
+
+	r.connMu.RLock()
+	if r.Connections[playerID] == pc {
+		pc.enqueue(state)
+		if since > 0 {
+			r.replayLocked(pc, since)
+		}
+	}
+	r.connMu.RUnlock()
+	r.eventMu.Unlock()
+
+	// Читать сокет будет вызывающий через pc.ReadPump
+	go pc.writePump()
+
+	if reconnected {
+		r.sendPresence(playerID, PresenceOnline)
+	}
+	return pc, reconnected, nil
+}
+
+// ConnectionLost вызывается, когда чтение из сокета упало. Игрок переходит
+// в состояние reconnecting, leave отправляется только по истечении окна.
+func (r *Room) ConnectionLost(pc *PlayerConnection) {
+	playerID := pc.player.ID
+
+	r.connMu.Lock()
+	if r.Connections[playerID] != pc {
+		// Соединение уже заменено или удалено
+		r.connMu.Unlock()
+		return
+	}
+	close(pc.send)
+	delete(r.Connections, playerID)
+
+	r.Presence[playerID] = PresenceReconnecting
+	r.graceTimers[playerID] = clock.AfterFunc(reconnectGracePeriod, func() {
+		r.reconnectExpired(playerID)
+	})
+	r.connMu.Unlock()
+
+	r.sendPresence(playerID, PresenceReconnecting)
+
+	if r.IsHost(playerID) {
+		r.scheduleHostMigration(playerID)
+	}
+}
+
+func (r *Room) reconnectExpired(playerID string) {
+	r.connMu.Lock()
+	_, connected := r.Connections[playerID]
+	if connected || r.Presence[playerID] != PresenceReconnecting {
+		r.connMu.Unlock()
+		return
+	}
+	r.Presence[playerID] = PresenceOffline
+	delete(r.graceTimers, playerID)
+	r.connMu.Unlock()
+
+	player := r.GetPlayer(playerID)
+	if player == nil {
+		return
+	}
+
+	r.sendMessageToAll(WSLeaveResponse{
+		Type:       "leave",
+		PlayerID:   playerID,
+		PlayerName: player.Name,
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+func (r *Room) sendPresence(playerID string, state PresenceState) {
+	r.sendTransientToAll(WSPresenceResponse{
+		Type:      "presence",
+		PlayerID:  playerID,
+		State:     state,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// presenceSnapshot копия состояний присутствия, игроки без записи offline
+func (r *Room) presenceSnapshot() map[string]PresenceState {
+	r.connMu.RLock()
+	defer r.connMu.RUnlock()
+
+	presence := make(map[string]PresenceState, len(r.Presence))
+	for id, state := range r.Presence {
+		presence[id] = state
+	}
+	return presence
+}
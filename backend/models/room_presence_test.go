@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func presenceOf(room *Room, playerID string) PresenceState {
+	return room.presenceSnapshot()[playerID]
+}
+
+func leaves(room *Room) int {
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+
+	count := 0
+	for _, event := range room.Events {
+		if _, ok := event.Payload.(WSLeaveResponse); ok {
+			count++
+		}
+	}
+	return count
+}
+
+func TestReconnectWithinGrace(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob")
+	bob := playerID(t, room, "bob")
+	watcher := dialRoom(t, room, playerID(t, room, "alice"))
+
+	dialRoom(t, room, bob).Close()
+	waitFor(t, "bob reconnecting", func() bool { return presenceOf(room, bob) == PresenceReconnecting })
+	if msg := readMessage(t, watcher, "presence"); msg["playerId"] != bob || msg["state"] != string(PresenceReconnecting) {
+		t.Fatalf("presence = %v, want bob reconnecting", msg)
+	}
+
+	c.Advance(reconnectGracePeriod - time.Second)
+	dialRoom(t, room, bob)
+	if got := presenceOf(room, bob); got != PresenceOnline {
+		t.Fatalf("presence after reconnect = %s", got)
+	}
+	if msg := readMessage(t, watcher, "presence"); msg["playerId"] != bob || msg["state"] != string(PresenceOnline) {
+		t.Fatalf("presence = %v, want bob online", msg)
+	}
+
+	c.Advance(time.Minute)
+	if got := presenceOf(room, bob); got != PresenceOnline || leaves(room) != 0 {
+		t.Fatalf("presence = %s, leaves = %d after the old grace timer", got, leaves(room))
+	}
+}
+
+func TestLeaveAfterGrace(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob")
+	bob := playerID(t, room, "bob")
+	watcher := dialRoom(t, room, playerID(t, room, "alice"))
+
+	dialRoom(t, room, bob).Close()
+	waitFor(t, "bob reconnecting", func() bool { return presenceOf(room, bob) == PresenceReconnecting })
+
+	c.Advance(reconnectGracePeriod - time.Second)
+	if leaves(room) != 0 {
+		t.Fatal("leave sent before the grace period ended")
+	}
+	c.Advance(time.Second)
+	if got := presenceOf(room, bob); got != PresenceOffline {
+		t.Fatalf("presence = %s, want offline", got)
+	}
+	if msg := readMessage(t, watcher, "leave"); msg["playerId"] != bob {
+		t.Fatalf("leave = %v, want bob", msg)
+	}
+	if leaves(room) != 1 {
+		t.Fatalf("leaves = %d, want 1", leaves(room))
+	}
+}
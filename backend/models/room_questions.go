@@ -0,0 +1,212 @@
+package models
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+type AnswerValue string
+
+const (
+	AnswerYes        AnswerValue = "yes"
+	AnswerNo         AnswerValue = "no"
+	AnswerMaybe      AnswerValue = "maybe"
+	AnswerIrrelevant AnswerValue = "irrelevant"
+)
+
+var answerValues = []AnswerValue{AnswerYes, AnswerNo, AnswerMaybe, AnswerIrrelevant}
+
+// Текстовые ответы старых клиентов, которые присылают только text
+var answerAliases = map[string]AnswerValue{
+	"yes":        AnswerYes,
+	"да":         AnswerYes,
+	"no":         AnswerNo,
+	"нет":        AnswerNo,
+	"maybe":      AnswerMaybe,
+	"возможно":   AnswerMaybe,
+	"irrelevant": AnswerIrrelevant,
+	"неважно":    AnswerIrrelevant,
+}
+
+func (v AnswerValue) Valid() bool {
+	for _, value := range answerValues {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAnswerValue приводит ответ к одному из значений, "" если не распознан
+func parseAnswerValue(text string) AnswerValue {
+	return answerAliases[strings.ToLower(strings.TrimSpace(text))]
+}
+
+// Question вопрос раунда с ответами игроков. Каждый игрок, кроме автора,
+// отвечает один раз, повторный ответ заменяет предыдущий.
+type Question struct {
+	ID         string                 `json:"id"`
+	PlayerID   string                 `json:"playerId"`
+	PlayerName string                 `json:"playerName"`
+	Text       string                 `json:"text"`
+	AskedAt    int64                  `json:"askedAt"`
+	Answers    map[string]AnswerValue `json:"answers"`
+	Tally      map[AnswerValue]int    `json:"tally"`
+	Vote       *QuestionVote          `json:"vote,omitempty"`
+
+	// Resolved ответ «нет» на вопрос уже передал ход
+	Resolved bool `json:"resolved"`
+}
+
+func newQuestionID() string {
+	return hex.EncodeToString(mustRandomBytes(8))
+}
+
+func newTally() map[AnswerValue]int {
+	tally := make(map[AnswerValue]int, len(answerValues))
+	for _, value := range answerValues {
+		tally[value] = 0
+	}
+	return tally
+}
+
+func (q Question) clone() Question {
+	answers := make(map[string]AnswerValue, len(q.Answers))
+	for id, value := range q.Answers {
+		answers[id] = value
+	}
+	tally := make(map[AnswerValue]int, len(q.Tally))
+	for value, count := range q.Tally {
+		tally[value] = count
+	}
+	q.Answers = answers
+	q.Tally = tally
+	if q.Vote != nil {
+		vote := *q.Vote
+		vote.Voters = append([]string(nil), q.Vote.Voters...)
+		q.Vote = &vote
+	}
+	return q
+}
+
+// questionsLocked копия вопросов раунда. Вызывается под dataMu.
+func (r *Room) questionsLocked() []Question {
+	questions := make([]Question, 0, len(r.Questions))
+	for _, q := range r.Questions {
+		questions = append(questions, q.clone())
+	}
+	return questions
+}
+
+func (r *Room) findQuestionLocked(questionID string) int {
+	for i, q := range r.Questions {
+		if q.ID == questionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// AskQuestion регистрирует вопрос и рассылает его с серверным ID.
+// В режиме большинства по вопросу открывается голосование.
+func (r *Room) AskQuestion(playerID, playerName, text string, timestamp int64) Question {
+	question := Question{
+		ID:         newQuestionID(),
+		PlayerID:   playerID,
+		PlayerName: playerName,
+		Text:       text,
+		AskedAt:    timestamp,
+		Answers:    make(map[string]AnswerValue),
+		Tally:      newTally(),
+		Vote:       r.newVote(playerID),
+	}
+
+	r.dataMu.Lock()
+	r.Questions = append(r.Questions, question)
+	r.scheduleVoteLocked(question)
+	r.dataMu.Unlock()
+
+	response := WSQuestionResponse{
+		Type:       "question",
+		QuestionID: question.ID,
+		PlayerID:   playerID,
+		PlayerName: playerName,
+		Text:       text,
+		Timestamp:  timestamp,
+	}
+	if question.Vote != nil {
+		response.Voters = question.Vote.Voters
+		response.ClosesAt = question.Vote.closesAt()
+	}
+	r.sendMessageToAll(response)
+
+	// Голосовать некому, итог сразу пустой
+	if question.Vote != nil && len(question.Vote.Voters) == 0 {
+		r.closeVote(question.ID, false)
+	}
+	return question.clone()
+}
+
+// AnswerQuestion записывает ответ игрока и рассылает ответ и новый подсчёт.
+// Пустой questionID означает последний заданный вопрос.
+func (r *Room) AnswerQuestion(playerID, playerName, questionID string, value AnswerValue, text string) (Question, error) {
+	r.dataMu.Lock()
+	index := len(r.Questions) - 1
+	if questionID != "" {
+		index = r.findQuestionLocked(questionID)
+	}
+	if index == -1 {
+		r.dataMu.Unlock()
+		return Question{}, badRequest("question %q not found", questionID)
+	}
+
+	question := &r.Questions[index]
+	if question.PlayerID == playerID {
+		r.dataMu.Unlock()
+		return Question{}, badRequest("you can't answer your own question")
+	}
+	if vote := question.Vote; vote != nil {
+		if vote.Closed {
+			r.dataMu.Unlock()
+			return Question{}, badRequest("voting on this question is closed")
+		}
+		if !vote.isVoter(playerID) {
+			r.dataMu.Unlock()
+			return Question{}, badRequest("you are not a voter on this question")
+		}
+	}
+
+	if prev, answered := question.Answers[playerID]; answered {
+		question.Tally[prev]--
+	}
+	question.Answers[playerID] = value
+	question.Tally[value]++
+	complete := question.Vote != nil && voteCompleteLocked(question)
+	result := question.clone()
+	r.dataMu.Unlock()
+
+	timestamp := time.Now().Unix()
+	r.sendMessageToAll(WSAnswerResponse{
+		Type:       "answer",
+		QuestionID: result.ID,
+		PlayerID:   playerID,
+		PlayerName: playerName,
+		Answer:     value,
+		Text:       text,
+		Timestamp:  timestamp,
+	})
+	r.sendMessageToAll(WSAnswerTallyResponse{
+		Type:       "answer_tally",
+		QuestionID: result.ID,
+		Tally:      result.Tally,
+		Answered:   len(result.Answers),
+		Timestamp:  timestamp,
+	})
+
+	// Все проголосовали, не ждём таймаута
+	if complete {
+		r.closeVote(result.ID, false)
+	}
+	return result, nil
+}
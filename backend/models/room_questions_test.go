@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// lastTally последнее answer_tally по вопросу из журнала комнаты
+func lastTally(t *testing.T, room *Room, questionID string) WSAnswerTallyResponse {
+	t.Helper()
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+
+	for i := len(room.Events) - 1; i >= 0; i-- {
+		if msg, ok := room.Events[i].Payload.(WSAnswerTallyResponse); ok && msg.QuestionID == questionID {
+			return msg
+		}
+	}
+	t.Fatalf("no answer_tally for question %s", questionID)
+	return WSAnswerTallyResponse{}
+}
+
+func TestAnswerTallyPerQuestion(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob", "carol")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+	carol := playerID(t, room, "carol")
+	room.dataMu.Lock()
+	room.Phase = PhaseGuessing
+	room.dataMu.Unlock()
+
+	first := room.AskQuestion(alice, "alice", "Am I real?", time.Now().Unix())
+	second := room.AskQuestion(bob, "bob", "Am I an animal?", time.Now().Unix())
+	answer := func(id, name, body string) {
+		t.Helper()
+		if err := room.HandleMessage(id, name, []byte(body)); err != nil {
+			t.Fatalf("%s: %v", body, err)
+		}
+	}
+
+	answer(bob, "bob", fmt.Sprintf(`{"type":"answer","questionId":%q,"answer":"yes"}`, first.ID))
+	answer(carol, "carol", fmt.Sprintf(`{"type":"answer","questionId":%q,"answer":"no"}`, first.ID))
+	// Без questionId ответ относится к последнему вопросу, текст старых
+	// клиентов разбирается в значение
+	answer(alice, "alice", `{"type":"answer","text":"Возможно"}`)
+	// Повторный ответ заменяет прежний, а не добавляется к нему
+	answer(bob, "bob", fmt.Sprintf(`{"type":"answer","questionId":%q,"answer":"no"}`, first.ID))
+
+	tests := []struct {
+		question Question
+		want     map[AnswerValue]int
+		answered int
+	}{
+		{first, map[AnswerValue]int{AnswerYes: 0, AnswerNo: 2, AnswerMaybe: 0, AnswerIrrelevant: 0}, 2},
+		{second, map[AnswerValue]int{AnswerYes: 0, AnswerNo: 0, AnswerMaybe: 1, AnswerIrrelevant: 0}, 1},
+	}
+	for _, tt := range tests {
+		tally := lastTally(t, room, tt.question.ID)
+		if tally.Answered != tt.answered || fmt.Sprint(tally.Tally) != fmt.Sprint(tt.want) {
+			t.Errorf("tally of %q = %v (%d answered), want %v (%d)",
+				tt.question.Text, tally.Tally, tally.Answered, tt.want, tt.answered)
+		}
+	}
+
+	err := room.HandleMessage(alice, "alice", []byte(fmt.Sprintf(`{"type":"answer","questionId":%q,"answer":"yes"}`, first.ID)))
+	if errorCode(err) != ErrCodeBadRequest {
+		t.Fatalf("answer to own question: err = %v, want %s", err, ErrCodeBadRequest)
+	}
+	if err := room.HandleMessage(bob, "bob", []byte(`{"type":"answer","questionId":"missing","answer":"yes"}`)); errorCode(err) != ErrCodeBadRequest {
+		t.Fatalf("answer to unknown question: err = %v, want %s", err, ErrCodeBadRequest)
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// ScoreEntry итоги игрока по всем раундам в комнате
+type ScoreEntry struct {
+	PlayerID   string `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	Points     int    `json:"points"`
+	Wins       int    `json:"wins"`
+	Rounds     int    `json:"rounds"`
+}
+
+// addRoundLocked добавляет посчитанные scoreRoundLocked очки раунда в таблицу
+// комнаты и в Player.Score. Вызывается под dataMu.
+func (r *Room) addRoundLocked(ranking []RankingEntry) {
+	r.Round++
+	for _, entry := range ranking {
+		score := r.Scoreboard[entry.PlayerID]
+		score.PlayerID = entry.PlayerID
+		score.PlayerName = entry.PlayerName
+		score.Rounds++
+		if entry.Won {
+			score.Wins++
+		}
+		score.Points += entry.Score.Total
+		r.Scoreboard[entry.PlayerID] = score
+	}
+
+	for i := range r.Players {
+		r.Players[i].Score = r.Scoreboard[r.Players[i].ID].Points
+	}
+}
+
+// scoreboardLocked таблица по убыванию очков. Вызывается под dataMu.
+func (r *Room) scoreboardLocked() []ScoreEntry {
+	scoreboard := make([]ScoreEntry, 0, len(r.Scoreboard))
+	for _, score := range r.Scoreboard {
+		scoreboard = append(scoreboard, score)
+	}
+	sort.Slice(scoreboard, func(i, j int) bool {
+		if scoreboard[i].Points != scoreboard[j].Points {
+			return scoreboard[i].Points > scoreboard[j].Points
+		}
+		return scoreboard[i].Wins > scoreboard[j].Wins
+	})
+	return scoreboard
+}
+
+// Rematch возвращает закончившуюся игру в лобби с теми же игроками.
+// Персонажи и победы сбрасываются, таблица очков сохраняется.
+func (r *Room) Rematch(shuffle bool) error {
+	r.dataMu.Lock()
+	prev, err := r.setPhaseLocked(PhaseLobby)
+	if err != nil {
+		r.dataMu.Unlock()
+		return err
+	}
+
+	r.Characters = make(map[string]string)
+	r.CharacterInfo = make(map[string]CharacterInfo)
+	r.WhoMakeFor = make(map[string]Player)
+	r.GuessCounts = make(map[string]int)
+	r.WrongGuesses = make(map[string]int)
+	r.GuessCooldowns = make(map[string]time.Time)
+	r.resetQuestionsLocked()
+	r.Winners = nil
+	r.GuessingStartedAt = time.Time{}
+	r.FinishedAt = time.Time{}
+	for i := range r.Players {
+		r.Players[i].IsWinner = false
+		r.Players[i].IsEliminated = false
+	}
+
+	if shuffle {
+		rand.Shuffle(len(r.Players), r.swapPlayers)
+	}
+
+	players := make([]Player, len(r.Players))
+	copy(players, r.Players)
+	scoreboard := r.scoreboardLocked()
+	round := r.Round
+	r.dataMu.Unlock()
+
+	r.broadcastPhase(prev, PhaseLobby)
+	r.sendMessageToAll(WSRematchResponse{
+		Type:       "rematch",
+		Players:    players,
+		Scoreboard: scoreboard,
+		Round:      round,
+		Text:       "Rematch! Waiting for the host to start",
+		Timestamp:  time.Now().Unix(),
+	})
+	return nil
+}
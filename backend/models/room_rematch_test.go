@@ -0,0 +1,67 @@
+package models
+
+import "testing"
+
+// gameOvers сообщения game_over из журнала комнаты
+func gameOvers(room *Room) []WSGameOverResponse {
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+
+	var results []WSGameOverResponse
+	for _, event := range room.Events {
+		if msg, ok := event.Payload.(WSGameOverResponse); ok {
+			results = append(results, msg)
+		}
+	}
+	return results
+}
+
+func TestRematchKeepsScoreboard(t *testing.T) {
+	useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+
+	startGuessing(t, room)
+	room.AddWinner(WSAddWinnerMessage{WinnerID: alice})
+	if phase := roomPhase(room); phase != PhaseFinished {
+		t.Fatalf("phase = %s after the first round", phase)
+	}
+	// StartGame начинает журнал заново, итоги раунда забираем до него
+	rounds := gameOvers(room)
+
+	if err := room.Rematch(false); err != nil {
+		t.Fatal(err)
+	}
+	room.dataMu.RLock()
+	first := room.Scoreboard[alice]
+	if room.Phase != PhaseLobby || room.Round != 1 || len(room.Winners) != 0 || len(room.Characters) != 0 {
+		t.Errorf("after rematch phase = %s, round = %d, winners = %v, characters = %v",
+			room.Phase, room.Round, room.Winners, room.Characters)
+	}
+	if first.Wins != 1 || first.Points == 0 || room.Players[0].Score != first.Points || room.Players[0].IsWinner {
+		t.Errorf("alice after rematch: score entry %+v, player %+v", first, room.Players[0])
+	}
+	room.dataMu.RUnlock()
+
+	startGuessing(t, room)
+	room.AddWinner(WSAddWinnerMessage{WinnerID: bob})
+
+	// Очки в таблице сумма очков обоих раундов
+	totals := make(map[string]int)
+	for _, over := range append(rounds, gameOvers(room)...) {
+		for _, entry := range over.Ranking {
+			totals[entry.PlayerID] += entry.Score.Total
+		}
+	}
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+	if room.Round != 2 {
+		t.Fatalf("round = %d, want 2", room.Round)
+	}
+	for _, id := range []string{alice, bob} {
+		score := room.Scoreboard[id]
+		if score.Rounds != 2 || score.Wins != 1 || score.Points != totals[id] {
+			t.Errorf("score of %s = %+v, want 2 rounds, 1 win, %d points", score.PlayerName, score, totals[id])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	ErrCodeBadRequest  = "bad_request"
+	ErrCodeUnknownType = "unknown_type"
+)
+
+// CommandContext кто и в какой комнате прислал сообщение
+type CommandContext struct {
+	Room       *Room
+	PlayerID   string
+	PlayerName string
+	Timestamp  int64
+}
+
+// messageRoute обработка одного типа входящих сообщений:
+// декодирование, проверка и собственно выполнение
+type messageRoute struct {
+	decode   func(data []byte) (interface{}, error)
+	validate func(ctx *CommandContext, msg interface{}) error
+	handle   func(ctx *CommandContext, msg interface{}) error
+}
+
+var routes = make(map[string]messageRoute)
+
+// registerRoute регистрирует обработчик сообщения msgType. Сообщение
+// декодируется в T, validate может быть nil.
+func registerRoute[T any](msgType string, validate func(*CommandContext, *T) error, handle func(*CommandContext, *T) error) {
+	routes[msgType] = messageRoute{
+		decode: func(data []byte) (interface{}, error) {
+			msg := new(T)
+			if err := json.Unmarshal(data, msg); err != nil {
+				return nil, err
+			}
+			return msg, nil
+		},
+		validate: func(ctx *CommandContext, msg interface{}) error {
+			if validate == nil {
+				return nil
+			}
+			return validate(ctx, msg.(*T))
+		},
+		handle: func(ctx *CommandContext, msg interface{}) error {
+			return handle(ctx, msg.(*T))
+		},
+	}
+}
+
+// validators объединяет несколько проверок в одну
+func validators[T any](checks ...func(*CommandContext, *T) error) func(*CommandContext, *T) error {
+	return func(ctx *CommandContext, msg *T) error {
+		for _, check := range checks {
+			if err := check(ctx, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// hostOnly проверка прав для команд хоста
+func hostOnly[T any](action Action) func(*CommandContext, *T) error {
+	return func(ctx *CommandContext, _ *T) error {
+		return ctx.Room.Authorize(ctx.PlayerID, action)
+	}
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &CommandError{
+		Code:    ErrCodeBadRequest,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// HandleMessage единая точка входа для всех сообщений от клиента
+func (r *Room) HandleMessage(playerID, playerName string, data []byte) error {
+	var base WSMessageBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return badRequest("error parsing message type: %v", err)
+	}
+
+	route, exists := routes[base.Type]
+	if !exists {
+		return &CommandError{
+			Code:    ErrCodeUnknownType,
+			Message: "unknown message type: " + base.Type,
+		}
+	}
+
+	msg, err := route.decode(data)
+	if err != nil {
+		return badRequest("error parsing %s: %v", base.Type, err)
+	}
+
+	ctx := &CommandContext{
+		Room:       r,
+		PlayerID:   playerID,
+		PlayerName: playerName,
+		Timestamp:  time.Now().Unix(),
+	}
+
+	if err := route.validate(ctx, msg); err != nil {
+		return err
+	}
+	return route.handle(ctx, msg)
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// ScoringRules правила начисления очков за раунд
+type ScoringRules struct {
+	// PositionPoints очки за 1-е, 2-е и т.д. место среди отгадавших,
+	// места за пределами списка очков не дают
+	PositionPoints []int `json:"positionPoints"`
+	// PerQuestion очки за каждый вопрос сверх FreeQuestions, обычно отрицательные
+	PerQuestion   int `json:"perQuestion"`
+	FreeQuestions int `json:"freeQuestions"`
+	// TimeBonus максимум за скорость, убывает до нуля к концу TimeWindow
+	TimeBonus  int           `json:"timeBonus"`
+	TimeWindow time.Duration `json:"timeWindow"`
+	// PerWrongGuess очки за каждую неверную догадку, обычно отрицательные
+	PerWrongGuess int `json:"perWrongGuess"`
+	// AssignerBonus получает загадавший, если его персонажа не отгадали
+	// быстрее AssignerAfter
+	AssignerBonus int           `json:"assignerBonus"`
+	AssignerAfter time.Duration `json:"assignerAfter"`
+}
+
+const DefaultScoringRules = "classic"
+
+// ScoringRuleSets наборы правил, из которых выбирает комната
+var ScoringRuleSets = map[string]ScoringRules{
+	"classic": {
+		PositionPoints: []int{10, 7, 5, 3, 2, 1},
+		PerQuestion:    -1,
+		FreeQuestions:  10,
+		TimeBonus:      5,
+		TimeWindow:     10 * time.Minute,
+		PerWrongGuess:  -2,
+		AssignerBonus:  3,
+		AssignerAfter:  5 * time.Minute,
+	},
+	"speed": {
+		PositionPoints: []int{5, 3, 1},
+		PerQuestion:    -1,
+		FreeQuestions:  5,
+		TimeBonus:      15,
+		TimeWindow:     5 * time.Minute,
+		PerWrongGuess:  -3,
+		AssignerBonus:  2,
+		AssignerAfter:  3 * time.Minute,
+	},
+	"casual": {
+		PositionPoints: []int{3, 2, 1},
+		AssignerBonus:  2,
+		AssignerAfter:  10 * time.Minute,
+	},
+}
+
+// ScoreBreakdown из чего сложились очки игрока за раунд
+type ScoreBreakdown struct {
+	Position     int `json:"position"`
+	Questions    int `json:"questions"`
+	Time         int `json:"time"`
+	WrongGuesses int `json:"wrongGuesses"`
+	Assigner     int `json:"assigner"`
+	Total        int `json:"total"`
+}
+
+// ScoringRuleNames названия наборов правил по алфавиту
+func ScoringRuleNames() []string {
+	names := make([]string, 0, len(ScoringRuleSets))
+	for name := range ScoringRuleSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Room) scoringRulesLocked() ScoringRules {
+	if rules, exists := ScoringRuleSets[r.Settings.ScoringRules]; exists {
+		return rules
+	}
+	return ScoringRuleSets[DefaultScoringRules]
+}
+
+// questionsAskedLocked сколько вопросов задал каждый игрок за раунд.
+// Вызывается под dataMu.
+func (r *Room) questionsAskedLocked() map[string]int {
+	asked := make(map[string]int)
+	for _, q := range r.Questions {
+		asked[q.PlayerID]++
+	}
+	return asked
+}
+
+// scoreRoundLocked считает очки за раунд по правилам комнаты и записывает
+// их в ranking. Вызывается под dataMu.
+func (r *Room) scoreRoundLocked(ranking []RankingEntry) {
+	rules := r.scoringRulesLocked()
+	asked := r.questionsAskedLocked()
+
+	// Сколько продержался персонаж каждого игрока
+	lasted := make(map[string]time.Duration, len(ranking))
+	for _, entry := range ranking {
+		lasted[entry.PlayerID] = time.Duration(entry.DurationMs) * time.Millisecond
+	}
+
+	for i := range ranking {
+		entry := &ranking[i]
+		score := ScoreBreakdown{}
+
+		if entry.Won {
+			if entry.Position <= len(rules.PositionPoints) {
+				score.Position = rules.PositionPoints[entry.Position-1]
+			}
+			if rules.TimeWindow > 0 {
+				left := rules.TimeWindow - lasted[entry.PlayerID]
+				if left > 0 {
+					score.Time = int(float64(rules.TimeBonus) * float64(left) / float64(rules.TimeWindow))
+				}
+			}
+		}
+
+		if extra := asked[entry.PlayerID] - rules.FreeQuestions; extra > 0 {
+			score.Questions = extra * rules.PerQuestion
+		}
+		score.WrongGuesses = r.WrongGuesses[entry.PlayerID] * rules.PerWrongGuess
+
+		if target, exists := r.WhoMakeFor[entry.PlayerID]; exists && r.Characters[target.ID] != "" {
+			if d, played := lasted[target.ID]; played && d >= rules.AssignerAfter {
+				score.Assigner = rules.AssignerBonus
+			}
+		}
+
+		score.Total = score.Position + score.Questions + score.Time + score.WrongGuesses + score.Assigner
+		if score.Total < 0 {
+			score.Total = 0
+		}
+		entry.Score = score
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoringRuleSets(t *testing.T) {
+	tests := []struct {
+		rules string
+		want  map[string]ScoreBreakdown
+	}{
+		{"classic", map[string]ScoreBreakdown{
+			"alice": {Position: 10, Time: 4, Questions: -2, Assigner: 3, Total: 15},
+			"bob":   {Position: 7, Time: 2, WrongGuesses: -2, Assigner: 3, Total: 10},
+			"carol": {},
+		}},
+		{"speed", map[string]ScoreBreakdown{
+			"alice": {Position: 5, Time: 9, Questions: -7, Assigner: 2, Total: 9},
+			"bob":   {Position: 3, WrongGuesses: -3, Assigner: 2, Total: 2},
+			"carol": {},
+		}},
+		{"casual", map[string]ScoreBreakdown{
+			"alice": {Position: 3, Total: 3},
+			"bob":   {Position: 2, Assigner: 2, Total: 4},
+			"carol": {},
+		}},
+		// Неизвестный набор считается по правилам по умолчанию
+		{"unknown", map[string]ScoreBreakdown{
+			"alice": {Position: 10, Time: 4, Questions: -2, Assigner: 3, Total: 15},
+			"bob":   {Position: 7, Time: 2, WrongGuesses: -2, Assigner: 3, Total: 10},
+			"carol": {},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rules, func(t *testing.T) {
+			room := newTestRoom(t, "alice", "bob", "carol")
+			alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+			carol := playerID(t, room, "carol")
+			start := time.Unix(1700000000, 0)
+
+			room.dataMu.Lock()
+			defer room.dataMu.Unlock()
+			room.Settings.ScoringRules = tt.rules
+			// alice загадывает bob, bob загадывает carol, carol загадывает alice
+			for i, id := range []string{alice, bob, carol} {
+				room.WhoMakeFor[id] = room.Players[(i+1)%3]
+				room.Characters[id] = "character"
+			}
+			for i := 0; i < 12; i++ {
+				room.Questions = append(room.Questions, Question{PlayerID: alice})
+			}
+			room.WrongGuesses[bob] = 1
+			room.GuessingStartedAt = start
+			room.Winners = []WinRecord{
+				{PlayerID: alice, WonAt: start.Add(2 * time.Minute)},
+				{PlayerID: bob, WonAt: start.Add(6 * time.Minute)},
+			}
+			for i := range room.Players {
+				room.Players[i].IsWinner = room.Players[i].ID != carol
+			}
+
+			ranking := room.rankingLocked(start.Add(10 * time.Minute))
+			room.scoreRoundLocked(ranking)
+			for _, entry := range ranking {
+				if want := tt.want[entry.PlayerName]; entry.Score != want {
+					t.Errorf("%s: score = %+v, want %+v", entry.PlayerName, entry.Score, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScoreIsNeverNegative(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	alice := playerID(t, room, "alice")
+
+	room.dataMu.Lock()
+	defer room.dataMu.Unlock()
+	room.WrongGuesses[alice] = 10
+	ranking := room.rankingLocked(time.Now())
+	room.scoreRoundLocked(ranking)
+	if score := ranking[0].Score; score.WrongGuesses != -20 || score.Total != 0 {
+		t.Fatalf("score = %+v, want -20 for guesses and a total of 0", score)
+	}
+}
@@ -0,0 +1,244 @@
+package models
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RoomSettings настройки комнаты. Задаются при создании и меняются хостом
+// в лобби командой update_settings. Нулевые значения означают «по умолчанию».
+type RoomSettings struct {
+	// MaxPlayers предел игроков без учёта зрителей, 0 без ограничения
+	MaxPlayers int `json:"maxPlayers"`
+
+	// Password новый пароль: nil оставляет прежний, пустая строка снимает.
+	// Хранится только bcrypt-хэш. HasPassword выводится из хэша в public,
+	// значение от клиента не используется.
+	Password    *string `json:"password,omitempty"`
+	HasPassword bool    `json:"hasPassword"`
+
+	// InviteOnly входить можно только по приглашению, пароль не принимается.
+	// Создатель входит в комнату сразу при создании, поэтому приглашения
+	// есть кому выдавать.
+	InviteOnly bool `json:"inviteOnly"`
+
+	TurnMode       bool `json:"turnMode"`
+	MajorityVote   bool `json:"majorityVote"`
+	VoteTimeoutSec int  `json:"voteTimeoutSec"`
+
+	// Таймеры хода и всего угадывания, 0 отключает таймер
+	TurnTimeoutSec int `json:"turnTimeoutSec"`
+	GameTimeoutSec int `json:"gameTimeoutSec"`
+
+	WrongGuessPenalty GuessPenalty    `json:"wrongGuessPenalty"`
+	GuessCooldownSec  int             `json:"guessCooldownSec"`
+	MaxWrongGuesses   int             `json:"maxWrongGuesses"`
+	MatchStrictness   MatchStrictness `json:"matchStrictness"`
+
+	ScoringRules    string `json:"scoringRules"`
+	Language        string `json:"language"`
+	AllowSpectators bool   `json:"allowSpectators"`
+
+	// Deck колода комнаты, с AutoAssign персонажей раздаёт сервер.
+	// Без DeckLanguage карточки отбираются по языку комнаты.
+	Deck           string `json:"deck"`
+	DeckCategory   string `json:"deckCategory"`
+	DeckDifficulty string `json:"deckDifficulty"`
+	DeckLanguage   string `json:"deckLanguage"`
+	AutoAssign     bool   `json:"autoAssign"`
+}
+
+// Границы настроек, длительности в секундах
+const (
+	maxPlayersLimit   = 20
+	maxPasswordLength = 64
+	minVoteTimeoutSec = 5
+	maxVoteTimeoutSec = 300
+	minTurnTimeoutSec = 10
+	maxTurnTimeoutSec = 600
+	minGameTimeoutSec = 60
+	maxGameTimeoutSec = 7200
+	minCooldownSec    = 5
+	maxCooldownSec    = 300
+	maxWrongGuesses   = 10
+)
+
+// Код языка вида ru, en или pt-br
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+func DefaultRoomSettings() RoomSettings {
+	return RoomSettings{
+		VoteTimeoutSec:    int(DefaultVoteTimeout / time.Second),
+		WrongGuessPenalty: PenaltyLoseTurn,
+		GuessCooldownSec:  int(DefaultGuessCooldown / time.Second),
+		MaxWrongGuesses:   DefaultMaxWrongGuesses,
+		MatchStrictness:   MatchNormal,
+		ScoringRules:      DefaultScoringRules,
+	}
+}
+
+// inRange 0 значит «не задано» и допустим всегда
+func inRange(value, min, max int) bool {
+	return value == 0 || (value >= min && value <= max)
+}
+
+// Validate проверяет настройки и подставляет значения по умолчанию
+// вместо незаданных
+func (s *RoomSettings) Validate() error {
+	s.Language = strings.ToLower(strings.TrimSpace(s.Language))
+	s.DeckLanguage = strings.ToLower(strings.TrimSpace(s.DeckLanguage))
+
+	if !inRange(s.MaxPlayers, 2, maxPlayersLimit) {
+		return badRequest("maxPlayers must be between 2 and %d", maxPlayersLimit)
+	}
+	if s.Password != nil && len(*s.Password) > maxPasswordLength {
+		return badRequest("password is longer than %d bytes", maxPasswordLength)
+	}
+	if !inRange(s.VoteTimeoutSec, minVoteTimeoutSec, maxVoteTimeoutSec) {
+		return badRequest("voteTimeoutSec must be between %d and %d", minVoteTimeoutSec, maxVoteTimeoutSec)
+	}
+	if !inRange(s.TurnTimeoutSec, minTurnTimeoutSec, maxTurnTimeoutSec) {
+		return badRequest("turnTimeoutSec must be between %d and %d", minTurnTimeoutSec, maxTurnTimeoutSec)
+	}
+	if !inRange(s.GameTimeoutSec, minGameTimeoutSec, maxGameTimeoutSec) {
+		return badRequest("gameTimeoutSec must be between %d and %d", minGameTimeoutSec, maxGameTimeoutSec)
+	}
+
+	if s.WrongGuessPenalty != "" && !s.WrongGuessPenalty.Valid() {
+		return badRequest("wrongGuessPenalty must be lose_turn, cooldown or elimination")
+	}
+	if !inRange(s.GuessCooldownSec, minCooldownSec, maxCooldownSec) {
+		return badRequest("guessCooldownSec must be between %d and %d", minCooldownSec, maxCooldownSec)
+	}
+	if !inRange(s.MaxWrongGuesses, 1, maxWrongGuesses) {
+		return badRequest("maxWrongGuesses must be between 1 and %d", maxWrongGuesses)
+	}
+	if s.MatchStrictness != "" && !s.MatchStrictness.Valid() {
+		return badRequest("matchStrictness must be strict, normal or lenient")
+	}
+
+	if _, exists := ScoringRuleSets[s.ScoringRules]; s.ScoringRules != "" && !exists {
+		return badRequest("unknown scoringRules, expected one of: %s", strings.Join(ScoringRuleNames(), ", "))
+	}
+	if s.Language != "" && !languagePattern.MatchString(s.Language) {
+		return badRequest("language must be a language code like en or ru")
+	}
+	if s.DeckLanguage != "" && !languagePattern.MatchString(s.DeckLanguage) {
+		return badRequest("deckLanguage must be a language code like en or ru")
+	}
+
+	if _, exists := GetDeck(s.Deck); s.Deck != "" && !exists {
+		return badRequest("deck %q not found", s.Deck)
+	}
+	if s.AutoAssign && s.Deck == "" {
+		return badRequest("autoAssign requires a deck")
+	}
+
+	defaults := DefaultRoomSettings()
+	if s.VoteTimeoutSec == 0 {
+		s.VoteTimeoutSec = defaults.VoteTimeoutSec
+	}
+	if s.WrongGuessPenalty == "" {
+		s.WrongGuessPenalty = defaults.WrongGuessPenalty
+	}
+	if s.GuessCooldownSec == 0 {
+		s.GuessCooldownSec = defaults.GuessCooldownSec
+	}
+	if s.MaxWrongGuesses == 0 {
+		s.MaxWrongGuesses = defaults.MaxWrongGuesses
+	}
+	if s.MatchStrictness == "" {
+		s.MatchStrictness = defaults.MatchStrictness
+	}
+	if s.ScoringRules == "" {
+		s.ScoringRules = defaults.ScoringRules
+	}
+	return nil
+}
+
+// public настройки для клиентов и хранилища, без пароля
+func (s RoomSettings) public(passwordHash string) RoomSettings {
+	s.Password = nil
+	s.HasPassword = passwordHash != ""
+	return s
+}
+
+func (s RoomSettings) voteTimeout() time.Duration {
+	return time.Duration(s.VoteTimeoutSec) * time.Second
+}
+
+func (s RoomSettings) turnTimeout() time.Duration {
+	return time.Duration(s.TurnTimeoutSec) * time.Second
+}
+
+func (s RoomSettings) gameTimeout() time.Duration {
+	return time.Duration(s.GameTimeoutSec) * time.Second
+}
+
+func (s RoomSettings) guessCooldown() time.Duration {
+	return time.Duration(s.GuessCooldownSec) * time.Second
+}
+
+func (s RoomSettings) deckFilter() DeckFilter {
+	language := s.DeckLanguage
+	if language == "" {
+		language = s.Language
+	}
+	return DeckFilter{
+		Category:   s.DeckCategory,
+		Difficulty: s.DeckDifficulty,
+		Language:   language,
+	}
+}
+
+// UpdateSettings применяет частичное изменение настроек: поля, которых нет
+// в patch, остаются прежними. Менять настройки можно только в лобби.
+func (r *Room) UpdateSettings(patch json.RawMessage) (RoomSettings, error) {
+	r.dataMu.RLock()
+	settings := r.Settings
+	r.dataMu.RUnlock()
+
+	if err := json.Unmarshal(patch, &settings); err != nil {
+		return RoomSettings{}, badRequest("invalid settings: %v", err)
+	}
+	if err := settings.Validate(); err != nil {
+		return RoomSettings{}, err
+	}
+	hash, passwordChanged, err := settings.takePassword()
+	if err != nil {
+		return RoomSettings{}, err
+	}
+
+	r.dataMu.Lock()
+	if r.Phase != PhaseLobby {
+		r.dataMu.Unlock()
+		return RoomSettings{}, &CommandError{
+			Code:    ErrCodeWrongPhase,
+			Message: "settings can only be changed in the lobby",
+		}
+	}
+	if players := r.playerCountLocked(); settings.MaxPlayers != 0 && players > settings.MaxPlayers {
+		r.dataMu.Unlock()
+		return RoomSettings{}, badRequest("room already has %d players", players)
+	}
+	r.Settings = settings
+	if passwordChanged {
+		r.PasswordHash = hash
+	}
+	public := settings.public(r.PasswordHash)
+	r.dataMu.Unlock()
+
+	r.persist()
+	if passwordChanged {
+		r.renewSessions()
+	}
+
+	r.sendMessageToAll(WSSettingsUpdatedResponse{
+		Type:      "settings_updated",
+		Settings:  public,
+		Timestamp: time.Now().Unix(),
+	})
+	return public, nil
+}
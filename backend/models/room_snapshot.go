@@ -3,14 +3,34 @@ package models
 // Структура запроса снимка
 type snapshotRequest struct {
 	playerID   string
+	since      int64
 	responseCh chan RoomSnapshot
 }
 
 // Структура снимка комнаты
 type RoomSnapshot struct {
-	Code       string
-	Players    []Player
-	Started    bool
-	Characters map[string]string
-	Messages   []interface{}
+	Code          string
+	HostID        string
+	Players       []Player
+	Presence      map[string]PresenceState
+	Started       bool
+	Phase         GamePhase
+	Characters    map[string]string
+	CharacterInfo map[string]CharacterInfo
+	Questions     []Question
+	Round         int
+	Scoreboard    []ScoreEntry
+	Events        []Event
+	LastSeq       int64
+
+	Settings       RoomSettings
+	ActivePlayerID string
+	TurnRemaining  int64
+	GameRemaining  int64
+	Paused         bool
+	PausedAt       int64
+	WrongGuesses   map[string]int
+
+	// EventsTruncated часть событий после since уже не хранится
+	EventsTruncated bool
 }
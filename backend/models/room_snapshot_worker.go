@@ -7,42 +7,45 @@ import (
 
 func (r *Room) snapshotWorker() {
 	for req := range r.snapshotRequests {
+		presence := r.presenceSnapshot()
+
 		r.dataMu.RLock()
-		
+
 		// Получаем видимые персонажи для игрока
-		visibleCharacters := make(map[string]string)
-		for pid, char := range r.Characters {
-			if pid != req.playerID {
-				visibleCharacters[pid] = char
-			} else if char != "" {
-				visibleCharacters[pid] = "?"
-			}
-		}
+		visibleCharacters := r.visibleCharactersLocked(req.playerID)
 
-		// Фильтруем сообщения
-		messages := make([]interface{}, 0, len(r.Messages))
-		for _, message := range r.Messages {
-			switch msg := message.(type) {
-			case WSSetCharacterResponse:
-				if msg.PlayerID == req.playerID && msg.Character != "?" {
-					continue
-				}
-			}
-			messages = append(messages, message)
-		}
+		// Фильтруем события
+		events, truncated := r.eventsSince(req.playerID, req.since)
 
 		// Копируем players
 		playersCopy := make([]Player, len(r.Players))
 		copy(playersCopy, r.Players)
 
 		snapshot := RoomSnapshot{
-			Code:       r.Code,
-			Players:    playersCopy,
-			Started:    r.Started,
-			Characters: visibleCharacters,
-			Messages:   messages,
+			Code:           r.Code,
+			HostID:         r.HostID,
+			Players:        playersCopy,
+			Presence:       presence,
+			Started:        r.Started,
+			Phase:          r.Phase,
+			Settings:       r.Settings.public(r.PasswordHash),
+			ActivePlayerID: r.ActivePlayerID,
+			TurnRemaining:  r.remainingLocked(r.TurnDeadline),
+			GameRemaining:  r.remainingLocked(r.GameDeadline),
+			Paused:         r.Paused,
+			PausedAt:       pausedAtUnix(r.PausedAt),
+			WrongGuesses:   r.wrongGuessesLocked(),
+			Characters:     visibleCharacters,
+			CharacterInfo:  r.visibleCharacterInfoLocked(req.playerID),
+			Questions:      r.questionsLocked(),
+			Round:          r.Round,
+			Scoreboard:     r.scoreboardLocked(),
+			Events:         events,
+			LastSeq:        r.LastSeq,
+
+			EventsTruncated: truncated,
 		}
-		
+
 		r.dataMu.RUnlock()
 
 		// Отправляем результат
@@ -55,15 +58,17 @@ func (r *Room) snapshotWorker() {
 	}
 }
 
-// GetSnapshotForPlayer запрашивает снимок комнаты для игрока
-func (r *Room) GetSnapshotForPlayer(playerID string) <-chan RoomSnapshot {
+// GetSnapshotForPlayer запрашивает снимок комнаты для игрока,
+// в снимок попадают только события с номером больше since
+func (r *Room) GetSnapshotForPlayer(playerID string, since int64) <-chan RoomSnapshot {
 	responseCh := make(chan RoomSnapshot, 1)
-	
+
 	req := snapshotRequest{
 		playerID:   playerID,
+		since:      since,
 		responseCh: responseCh,
 	}
-	
+
 	select {
 	case r.snapshotRequests <- req:
 		return responseCh
@@ -0,0 +1,286 @@
+package models
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// RoomTTL время жизни комнаты, после которого её удаляет CleanupOldRooms
+const RoomTTL = 2 * time.Hour
+
+// RoomStore хранилище комнат. Живые комнаты (с соединениями и воркерами)
+// всегда находятся в памяти, реализация может дополнительно сохранять их
+// состояние, чтобы оно пережило перезапуск сервера.
+type RoomStore interface {
+	// Add добавляет новую комнату, false если код уже занят
+	Add(room *Room) (bool, error)
+	Get(code string) (*Room, bool)
+	// Save сохраняет текущее состояние комнаты
+	Save(room *Room) error
+	// Delete удаляет комнату и возвращает её, nil если комнаты не было
+	Delete(code string) (*Room, error)
+	Rooms() []*Room
+}
+
+var store RoomStore = NewMemoryRoomStore()
+
+// SetRoomStore заменяет хранилище комнат, вызывается при старте сервера.
+// Если хранилище умеет хранить колоды, колоды тоже хранятся в нём.
+func SetRoomStore(s RoomStore) {
+	store = s
+	if decks, ok := s.(DeckStore); ok {
+		deckStore = decks
+	}
+}
+
+// PersistDelay за это время изменения комнаты копятся и записываются
+// одним сохранением, а не на каждое событие
+var PersistDelay = 500 * time.Millisecond
+
+// persist откладывает сохранение состояния комнаты. Повторные вызовы до
+// сохранения ничего не добавляют. Нельзя вызывать под dataMu.
+func (r *Room) persist() {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	if r.saveScheduled {
+		return
+	}
+	r.saveScheduled = true
+	// Запись на диск не игровое время, поэтому не через clock
+	time.AfterFunc(PersistDelay, r.save)
+}
+
+func (r *Room) save() {
+	r.saveMu.Lock()
+	r.saveScheduled = false
+	r.saveMu.Unlock()
+
+	if err := store.Save(r); err != nil {
+		log.Printf("Error saving room %s: %v", r.Code, err)
+	}
+}
+
+// roomRecord сериализуемое состояние комнаты
+type roomRecord struct {
+	Code       string            `json:"code"`
+	HostID     string            `json:"host_id"`
+	Players    []Player          `json:"players"`
+	Started    bool              `json:"started"`
+	Phase      GamePhase         `json:"phase"`
+	Characters map[string]string `json:"characters"`
+	WhoMakeFor map[string]Player `json:"who_make_for"`
+	CreatedAt  time.Time         `json:"created_at"`
+
+	CharacterInfo map[string]CharacterInfo `json:"character_info,omitempty"`
+
+	Settings     *RoomSettings `json:"settings,omitempty"`
+	PasswordHash string        `json:"password_hash,omitempty"`
+
+	SessionNonces map[string]string `json:"session_nonces,omitempty"`
+
+	ActivePlayerID string    `json:"active_player_id"`
+	TurnDeadline   time.Time `json:"turn_deadline"`
+	GameDeadline   time.Time `json:"game_deadline"`
+
+	Paused   bool      `json:"paused"`
+	PausedAt time.Time `json:"paused_at"`
+
+	GuessCooldowns map[string]time.Time `json:"guess_cooldowns,omitempty"`
+
+	Questions []Question `json:"questions,omitempty"`
+
+	Winners           []WinRecord    `json:"winners,omitempty"`
+	GuessCounts       map[string]int `json:"guess_counts,omitempty"`
+	WrongGuesses      map[string]int `json:"wrong_guesses,omitempty"`
+	GuessingStartedAt time.Time      `json:"guessing_started_at"`
+	FinishedAt        time.Time      `json:"finished_at"`
+
+	Round      int                   `json:"round"`
+	Scoreboard map[string]ScoreEntry `json:"scoreboard,omitempty"`
+
+	RoundPlayers int `json:"round_players"`
+
+	Events  []eventRecord `json:"events"`
+	LastSeq int64         `json:"last_seq"`
+}
+
+type eventRecord struct {
+	Seq     int64           `json:"seq"`
+	ID      string          `json:"id"`
+	Payload json.RawMessage `json:"payload"`
+	Hidden  []string        `json:"hidden,omitempty"`
+}
+
+func (r *Room) record() (roomRecord, error) {
+	r.dataMu.RLock()
+	defer r.dataMu.RUnlock()
+
+	events := make([]eventRecord, 0, len(r.Events))
+	for _, event := range r.Events {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			return roomRecord{}, err
+		}
+		events = append(events, eventRecord{
+			Seq:     event.Seq,
+			ID:      event.ID,
+			Payload: payload,
+			Hidden:  event.Hidden,
+		})
+	}
+
+	// Пароль в записи не попадает даже случайно, только PasswordHash
+	settings := r.Settings.public(r.PasswordHash)
+	return roomRecord{
+		Code:           r.Code,
+		HostID:         r.HostID,
+		Players:        r.Players,
+		Started:        r.Started,
+		Phase:          r.Phase,
+		Settings:       &settings,
+		PasswordHash:   r.PasswordHash,
+		SessionNonces:  r.SessionNonces,
+		ActivePlayerID: r.ActivePlayerID,
+		TurnDeadline:   r.TurnDeadline,
+		GameDeadline:   r.GameDeadline,
+		Paused:         r.Paused,
+		PausedAt:       r.PausedAt,
+
+		GuessCooldowns: r.GuessCooldowns,
+
+		Characters:    r.Characters,
+		CharacterInfo: r.CharacterInfo,
+		WhoMakeFor:    r.WhoMakeFor,
+		CreatedAt:     r.CreatedAt,
+
+		Questions: r.Questions,
+
+		Winners:           r.Winners,
+		GuessCounts:       r.GuessCounts,
+		WrongGuesses:      r.WrongGuesses,
+		GuessingStartedAt: r.GuessingStartedAt,
+		FinishedAt:        r.FinishedAt,
+
+		Round:      r.Round,
+		Scoreboard: r.Scoreboard,
+
+		RoundPlayers: r.RoundPlayers,
+
+		Events:  events,
+		LastSeq: r.LastSeq,
+	}, nil
+}
+
+func restoreRoom(rec roomRecord) (*Room, error) {
+	events := make([]Event, 0, len(rec.Events))
+	for _, stored := range rec.Events {
+		payload, err := decodeStoredMessage(stored.Payload)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, Event{
+			Seq:     stored.Seq,
+			ID:      stored.ID,
+			Payload: payload,
+			Hidden:  stored.Hidden,
+		})
+	}
+
+	room := newRoom(rec.Code, rec.CreatedAt)
+	room.HostID = rec.HostID
+	room.Started = rec.Started
+	room.Phase = rec.Phase
+	if rec.Settings != nil {
+		// Старые записи без настроек остаются со значениями по умолчанию
+		room.Settings = *rec.Settings
+	}
+	room.PasswordHash = rec.PasswordHash
+	// Записи, где пароль хранился в настройках открытым текстом
+	hash, changed, err := room.Settings.takePassword()
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		room.PasswordHash = hash
+	}
+	for id, nonce := range rec.SessionNonces {
+		room.SessionNonces[id] = nonce
+	}
+	room.ActivePlayerID = rec.ActivePlayerID
+	room.TurnDeadline = rec.TurnDeadline
+	room.GameDeadline = rec.GameDeadline
+	room.Paused = rec.Paused
+	room.PausedAt = rec.PausedAt
+	for id, until := range rec.GuessCooldowns {
+		room.GuessCooldowns[id] = until
+	}
+	if room.Phase == "" && rec.Started {
+		// Записи, сделанные до появления фаз
+		room.Phase = PhaseAssigning
+	} else if room.Phase == "" {
+		room.Phase = PhaseLobby
+	}
+	room.Events = events
+	room.LastSeq = rec.LastSeq
+
+	if rec.Players != nil {
+		room.Players = rec.Players
+	}
+	for id, character := range rec.Characters {
+		room.Characters[id] = character
+	}
+	for id, info := range rec.CharacterInfo {
+		room.CharacterInfo[id] = info
+	}
+	for id, player := range rec.WhoMakeFor {
+		room.WhoMakeFor[id] = player
+	}
+	for id, count := range rec.GuessCounts {
+		room.GuessCounts[id] = count
+	}
+	for id, count := range rec.WrongGuesses {
+		room.WrongGuesses[id] = count
+	}
+	room.Questions = rec.Questions
+	room.Winners = rec.Winners
+	room.GuessingStartedAt = rec.GuessingStartedAt
+	room.FinishedAt = rec.FinishedAt
+	room.Round = rec.Round
+	room.RoundPlayers = rec.RoundPlayers
+	for id, score := range rec.Scoreboard {
+		room.Scoreboard[id] = score
+	}
+
+	// Незакрытые голосования и таймеры продолжаются с оставшимся временем.
+	// Таймер может сработать сразу, поэтому под dataMu, как и в игре
+	room.dataMu.Lock()
+	for _, question := range room.Questions {
+		room.scheduleVoteLocked(question)
+	}
+	room.resumeTimersLocked()
+	room.dataMu.Unlock()
+
+	return room, nil
+}
+
+// decodeStoredMessage восстанавливает типы сообщений, которые фильтруются
+// в Event.visibleTo, остальные отдаются клиентам как есть
+func decodeStoredMessage(data json.RawMessage) (interface{}, error) {
+	var base WSMessageBase
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, err
+	}
+
+	switch base.Type {
+	case "set_character":
+		var msg WSSetCharacterResponse
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	return data, nil
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryRoomStore хранит комнаты и колоды только в памяти процесса
+type MemoryRoomStore struct {
+	mu    sync.RWMutex
+	rooms map[string]*Room
+	decks map[string]*Deck
+}
+
+func NewMemoryRoomStore() *MemoryRoomStore {
+	return &MemoryRoomStore{
+		rooms: make(map[string]*Room),
+		decks: make(map[string]*Deck),
+	}
+}
+
+func (s *MemoryRoomStore) Add(room *Room) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.rooms[room.Code]; exists {
+		return false, nil
+	}
+	s.rooms[room.Code] = room
+	return true, nil
+}
+
+func (s *MemoryRoomStore) Get(code string) (*Room, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	room, exists := s.rooms[code]
+	return room, exists
+}
+
+func (s *MemoryRoomStore) Save(room *Room) error {
+	return nil
+}
+
+func (s *MemoryRoomStore) Delete(code string) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, exists := s.rooms[code]
+	if !exists {
+		return nil, nil
+	}
+	delete(s.rooms, code)
+	return room, nil
+}
+
+func (s *MemoryRoomStore) Rooms() []*Room {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Room, 0, len(s.rooms))
+	for _, room := range s.rooms {
+		result = append(result, room)
+	}
+	return result
+}
+
+// isLive проверяет, что в хранилище лежит именно эта комната
+func (s *MemoryRoomStore) isLive(room *Room) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rooms[room.Code] == room
+}
+
+func (s *MemoryRoomStore) GetDeck(id string) (*Deck, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	deck, exists := s.decks[id]
+	return deck, exists
+}
+
+func (s *MemoryRoomStore) SaveDeck(deck *Deck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decks[deck.ID] = deck
+	return nil
+}
+
+func (s *MemoryRoomStore) DeleteDeck(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.decks[id]; !exists {
+		return false, nil
+	}
+	delete(s.decks, id)
+	return true, nil
+}
+
+func (s *MemoryRoomStore) Decks() []*Deck {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Deck, 0, len(s.decks))
+	for _, deck := range s.decks {
+		result = append(result, deck)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
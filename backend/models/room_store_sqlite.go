@@ -0,0 +1,187 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS rooms (
+	code       TEXT PRIMARY KEY,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	state      TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS decks (
+	id         TEXT PRIMARY KEY,
+	updated_at INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+`
+
+// SQLiteRoomStore держит живые комнаты в памяти и сохраняет их состояние
+// (игроков, персонажей, WhoMakeFor, Started и сообщения) в SQLite.
+// Колоды хранятся там же и целиком поднимаются в память при открытии.
+type SQLiteRoomStore struct {
+	*MemoryRoomStore
+	db *sql.DB
+}
+
+func NewSQLiteRoomStore(path string) (*SQLiteRoomStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite не любит параллельную запись
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &SQLiteRoomStore{
+		MemoryRoomStore: NewMemoryRoomStore(),
+		db:              db,
+	}
+	if err := s.loadDecks(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLiteRoomStore) Add(room *Room) (bool, error) {
+	added, err := s.MemoryRoomStore.Add(room)
+	if err != nil || !added {
+		return added, err
+	}
+	return true, s.Save(room)
+}
+
+func (s *SQLiteRoomStore) Save(room *Room) error {
+	// Удалённая комната не должна воскреснуть из-за запоздавшего сохранения
+	if !s.isLive(room) {
+		return nil
+	}
+
+	rec, err := room.record()
+	if err != nil {
+		return err
+	}
+	state, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO rooms (code, created_at, updated_at, state) VALUES (?, ?, ?, ?)
+		ON CONFLICT(code) DO UPDATE SET updated_at = excluded.updated_at, state = excluded.state`,
+		rec.Code, rec.CreatedAt.Unix(), time.Now().Unix(), string(state),
+	)
+	return err
+}
+
+func (s *SQLiteRoomStore) Delete(code string) (*Room, error) {
+	room, _ := s.MemoryRoomStore.Delete(code)
+	if _, err := s.db.Exec(`DELETE FROM rooms WHERE code = ?`, code); err != nil {
+		return room, err
+	}
+	return room, nil
+}
+
+// LoadActive поднимает из базы комнаты моложе maxAge, остальные удаляет
+func (s *SQLiteRoomStore) LoadActive(maxAge time.Duration) ([]*Room, error) {
+	cutoff := time.Now().Add(-maxAge).Unix()
+	if _, err := s.db.Exec(`DELETE FROM rooms WHERE created_at < ?`, cutoff); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT code, state FROM rooms`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var restored []*Room
+	for rows.Next() {
+		var code, state string
+		if err := rows.Scan(&code, &state); err != nil {
+			return restored, err
+		}
+
+		var rec roomRecord
+		if err := json.Unmarshal([]byte(state), &rec); err != nil {
+			log.Printf("Skipping broken room %s: %v", code, err)
+			continue
+		}
+
+		room, err := restoreRoom(rec)
+		if err != nil {
+			log.Printf("Skipping broken room %s: %v", code, err)
+			continue
+		}
+
+		if added, _ := s.MemoryRoomStore.Add(room); added {
+			restored = append(restored, room)
+		}
+	}
+
+	return restored, rows.Err()
+}
+
+func (s *SQLiteRoomStore) SaveDeck(deck *Deck) error {
+	data, err := json.Marshal(deck)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO decks (id, updated_at, data) VALUES (?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET updated_at = excluded.updated_at, data = excluded.data`,
+		deck.ID, time.Now().Unix(), string(data),
+	)
+	if err != nil {
+		return err
+	}
+	return s.MemoryRoomStore.SaveDeck(deck)
+}
+
+func (s *SQLiteRoomStore) DeleteDeck(id string) (bool, error) {
+	if _, err := s.db.Exec(`DELETE FROM decks WHERE id = ?`, id); err != nil {
+		return false, err
+	}
+	return s.MemoryRoomStore.DeleteDeck(id)
+}
+
+func (s *SQLiteRoomStore) loadDecks() error {
+	rows, err := s.db.Query(`SELECT id, data FROM decks`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return err
+		}
+
+		var deck Deck
+		if err := json.Unmarshal([]byte(data), &deck); err != nil {
+			log.Printf("Skipping broken deck %s: %v", id, err)
+			continue
+		}
+		s.MemoryRoomStore.SaveDeck(&deck)
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteRoomStore) Close() error {
+	return s.db.Close()
+}
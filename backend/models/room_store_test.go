@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordOmitsPassword(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	password := "hunter22"
+
+	room.dataMu.Lock()
+	room.Settings.Password = &password
+	room.PasswordHash = "$2a$10$hash"
+	room.dataMu.Unlock()
+
+	rec, err := room.record()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), password) {
+		t.Fatalf("record contains the raw password: %s", data)
+	}
+	if rec.PasswordHash == "" {
+		t.Fatal("record lost the password hash")
+	}
+}
+
+func TestSQLiteRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rooms.db")
+	db, err := NewSQLiteRoomStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	room := newRoom("SQL001", time.Now())
+	t.Cleanup(room.Close)
+	if _, err := db.Add(room); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := room.AddPlayer(name, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+
+	room.dataMu.Lock()
+	room.Phase = PhaseAssigning
+	room.WhoMakeFor[alice] = room.Players[1]
+	room.dataMu.Unlock()
+	if err := room.SetCharacter(WSSetCharacterMessage{PlayerID: alice, Character: "Yoda"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Save(room); err != nil {
+		t.Fatal(err)
+	}
+	want, err := room.record()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewSQLiteRoomStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	restored, err := reopened.LoadActive(RoomTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 1 {
+		t.Fatalf("restored %d rooms, want 1", len(restored))
+	}
+	loaded := restored[0]
+	t.Cleanup(loaded.Close)
+
+	got, err := loaded.record()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Players) != 2 || got.Players[0].ID != alice || got.Players[1].ID != bob {
+		t.Fatalf("players = %+v, want alice and bob", got.Players)
+	}
+	if got.Characters[bob] != "Yoda" || got.WhoMakeFor[alice].ID != bob {
+		t.Fatalf("characters = %v, whoMakeFor = %v", got.Characters, got.WhoMakeFor)
+	}
+	if len(got.Events) == 0 || len(got.Events) != len(want.Events) {
+		t.Fatalf("restored %d events, want %d", len(got.Events), len(want.Events))
+	}
+	for i := range want.Events {
+		if got.Events[i].ID != want.Events[i].ID || string(got.Events[i].Payload) != string(want.Events[i].Payload) {
+			t.Fatalf("event %d = %s, want %s", i, got.Events[i].Payload, want.Events[i].Payload)
+		}
+	}
+	if loaded.SessionToken(alice) != room.SessionToken(alice) {
+		t.Fatal("session token changed after the restart")
+	}
+}
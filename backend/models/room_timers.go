@@ -0,0 +1,179 @@
+package models
+
+import "time"
+
+const (
+	TimerTurn = "turn"
+	TimerGame = "game"
+)
+
+// TimerTickInterval как часто клиентам рассылается оставшееся время
+var TimerTickInterval = time.Second
+
+// remainingLocked сколько миллисекунд осталось до deadline, 0 если таймер
+// не запущен. На паузе время замирает. Вызывается под dataMu.
+func (r *Room) remainingLocked(deadline time.Time) int64 {
+	if deadline.IsZero() {
+		return 0
+	}
+	now := clock.Now()
+	if r.Paused {
+		now = r.PausedAt
+	}
+	remaining := deadline.Sub(now).Milliseconds()
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// startTurnTimerLocked отсчитывает ход игрока playerID заново.
+// Вызывается под dataMu.
+func (r *Room) startTurnTimerLocked(playerID string) {
+	r.stopTurnTimerLocked()
+	if r.Settings.TurnTimeoutSec <= 0 || playerID == "" || r.Phase != PhaseGuessing {
+		return
+	}
+	r.TurnDeadline = clock.Now().Add(r.Settings.turnTimeout())
+	r.scheduleTurnTimerLocked()
+}
+
+func (r *Room) scheduleTurnTimerLocked() {
+	if r.Paused {
+		return
+	}
+	playerID, deadline := r.ActivePlayerID, r.TurnDeadline
+	r.turnTimer = clock.AfterFunc(deadline.Sub(clock.Now()), func() {
+		r.turnExpired(playerID, deadline)
+	})
+	r.scheduleTickLocked()
+}
+
+func (r *Room) stopTurnTimerLocked() {
+	if r.turnTimer != nil {
+		r.turnTimer.Stop()
+		r.turnTimer = nil
+	}
+	r.TurnDeadline = time.Time{}
+}
+
+// startGameTimerLocked запускает общий таймер угадывания. Вызывается под dataMu.
+func (r *Room) startGameTimerLocked() {
+	if r.Settings.GameTimeoutSec <= 0 {
+		return
+	}
+	r.GameDeadline = clock.Now().Add(r.Settings.gameTimeout())
+	r.scheduleGameTimerLocked()
+}
+
+func (r *Room) scheduleGameTimerLocked() {
+	if r.Paused {
+		return
+	}
+	deadline := r.GameDeadline
+	r.gameTimer = clock.AfterFunc(deadline.Sub(clock.Now()), func() {
+		r.gameExpired(deadline)
+	})
+	r.scheduleTickLocked()
+}
+
+// stopTimersLocked останавливает все таймеры игры. Вызывается под dataMu.
+func (r *Room) stopTimersLocked() {
+	r.stopTurnTimerLocked()
+	if r.gameTimer != nil {
+		r.gameTimer.Stop()
+		r.gameTimer = nil
+	}
+	r.GameDeadline = time.Time{}
+	if r.tickTimer != nil {
+		r.tickTimer.Stop()
+		r.tickTimer = nil
+	}
+}
+
+// resumeTimersLocked перезапускает таймеры восстановленной комнаты
+// с оставшимся временем. Вызывается под dataMu.
+func (r *Room) resumeTimersLocked() {
+	if r.Phase != PhaseGuessing {
+		return
+	}
+	if !r.TurnDeadline.IsZero() && r.ActivePlayerID != "" {
+		r.scheduleTurnTimerLocked()
+	}
+	if !r.GameDeadline.IsZero() {
+		r.scheduleGameTimerLocked()
+	}
+}
+
+func (r *Room) scheduleTickLocked() {
+	if r.tickTimer == nil {
+		r.tickTimer = clock.AfterFunc(TimerTickInterval, r.tick)
+	}
+}
+
+// tick рассылает оставшееся время, пока запущен хотя бы один таймер.
+// В историю не попадает, после переподключения время есть в GameState.
+func (r *Room) tick() {
+	r.dataMu.Lock()
+	r.tickTimer = nil
+	if r.Paused || (r.TurnDeadline.IsZero() && r.GameDeadline.IsZero()) {
+		r.dataMu.Unlock()
+		return
+	}
+	msg := WSTimerTickResponse{
+		Type:            "timer_tick",
+		ActivePlayerID:  r.ActivePlayerID,
+		TurnRemainingMs: r.remainingLocked(r.TurnDeadline),
+		GameRemainingMs: r.remainingLocked(r.GameDeadline),
+		Timestamp:       clock.Now().Unix(),
+	}
+	r.scheduleTickLocked()
+	r.dataMu.Unlock()
+
+	r.sendTransientToAll(msg)
+}
+
+// turnExpired передаёт ход, если игрок не уложился в отведённое время
+func (r *Room) turnExpired(playerID string, deadline time.Time) {
+	r.dataMu.Lock()
+	if r.Paused || r.ActivePlayerID != playerID || !r.TurnDeadline.Equal(deadline) {
+		// Ход сменился раньше, таймер устарел
+		r.dataMu.Unlock()
+		return
+	}
+	r.turnTimer = nil
+	r.TurnDeadline = time.Time{}
+	r.dataMu.Unlock()
+
+	player := r.GetPlayer(playerID)
+	if player == nil {
+		return
+	}
+	r.sendMessageToAll(WSTimerExpiredResponse{
+		Type:      "timer_expired",
+		Timer:     TimerTurn,
+		PlayerID:  playerID,
+		Text:      player.Name + " ran out of time",
+		Timestamp: clock.Now().Unix(),
+	})
+	r.passTurn(playerID)
+}
+
+// gameExpired завершает игру, когда вышло общее время
+func (r *Room) gameExpired(deadline time.Time) {
+	r.dataMu.Lock()
+	if r.Paused || r.Phase != PhaseGuessing || !r.GameDeadline.Equal(deadline) {
+		r.dataMu.Unlock()
+		return
+	}
+	r.gameTimer = nil
+	r.dataMu.Unlock()
+
+	r.sendMessageToAll(WSTimerExpiredResponse{
+		Type:      "timer_expired",
+		Timer:     TimerGame,
+		Text:      "Time is up!",
+		Timestamp: clock.Now().Unix(),
+	})
+	r.EndGame(GameOverTimeUp)
+}
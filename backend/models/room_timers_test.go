@@ -0,0 +1,149 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// startTimedGame переводит комнату в угадывание с таймерами хода и игры
+func startTimedGame(t *testing.T, room *Room, turn, game time.Duration) {
+	t.Helper()
+	room.dataMu.Lock()
+	room.Settings.TurnMode = true
+	room.Settings.TurnTimeoutSec = int(turn / time.Second)
+	room.Settings.GameTimeoutSec = int(game / time.Second)
+	room.Phase = PhaseGuessing
+	room.GuessingStartedAt = clock.Now()
+	room.startGameTimerLocked()
+	room.setTurnLocked(room.Players[0].ID)
+	room.dataMu.Unlock()
+}
+
+func roomPhase(room *Room) GamePhase {
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+	return room.Phase
+}
+
+func TestTurnExpires(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+	startTimedGame(t, room, 30*time.Second, 0)
+
+	c.Advance(29 * time.Second)
+	if got := activePlayer(room); got != alice {
+		t.Fatalf("turn passed to %s before the timeout", got)
+	}
+	c.Advance(time.Second)
+	if got := activePlayer(room); got != bob {
+		t.Fatalf("active = %s after the timeout, want bob", got)
+	}
+
+	// Ход bob отсчитывается заново
+	c.Advance(30 * time.Second)
+	if got := activePlayer(room); got != alice {
+		t.Fatalf("active = %s after bob's timeout, want alice", got)
+	}
+}
+
+func TestGameExpires(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob")
+	startTimedGame(t, room, 0, 5*time.Minute)
+
+	c.Advance(5*time.Minute - time.Second)
+	if phase := roomPhase(room); phase != PhaseGuessing {
+		t.Fatalf("phase = %s before the game timeout", phase)
+	}
+	c.Advance(time.Second)
+	if phase := roomPhase(room); phase != PhaseFinished {
+		t.Fatalf("phase = %s after the game timeout, want %s", phase, PhaseFinished)
+	}
+
+	room.dataMu.RLock()
+	finishedAt := room.FinishedAt
+	room.dataMu.RUnlock()
+	if !finishedAt.Equal(c.Now()) {
+		t.Errorf("FinishedAt = %v, want the clock time %v", finishedAt, c.Now())
+	}
+}
+
+func TestPauseFreezesTimers(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+	startTimedGame(t, room, 30*time.Second, 2*time.Minute)
+
+	c.Advance(20 * time.Second)
+	if err := room.Pause(alice); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(time.Hour)
+	if got := activePlayer(room); got != alice {
+		t.Fatalf("turn passed to %s during the pause", got)
+	}
+	if phase := roomPhase(room); phase != PhaseGuessing {
+		t.Fatalf("game ended during the pause: phase = %s", phase)
+	}
+
+	room.dataMu.RLock()
+	turnLeft := room.remainingLocked(room.TurnDeadline)
+	room.dataMu.RUnlock()
+	if turnLeft != 10000 {
+		t.Fatalf("turn remaining on pause = %d ms, want 10000", turnLeft)
+	}
+
+	if err := room.Resume(alice); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(10 * time.Second)
+	if got := activePlayer(room); got != bob {
+		t.Fatalf("active = %s after the resumed turn ran out, want bob", got)
+	}
+
+	// От игры оставалось 100 секунд, 10 из них уже прошли
+	c.Advance(89 * time.Second)
+	if phase := roomPhase(room); phase != PhaseGuessing {
+		t.Fatalf("phase = %s before the shifted game deadline", phase)
+	}
+	c.Advance(time.Second)
+	if phase := roomPhase(room); phase != PhaseFinished {
+		t.Fatalf("phase = %s after the shifted game deadline, want %s", phase, PhaseFinished)
+	}
+}
+
+func TestPauseFreezesGuessCooldown(t *testing.T) {
+	c := useFakeClock(t)
+	room := newTestRoom(t, "alice", "bob")
+	alice := playerID(t, room, "alice")
+	startGuessing(t, room)
+
+	room.dataMu.Lock()
+	room.Settings.WrongGuessPenalty = PenaltyCooldown
+	room.Settings.GuessCooldownSec = 30
+	room.dataMu.Unlock()
+
+	canGuessNow := func() error {
+		return canGuess[WSGuessMessage]()(&CommandContext{Room: room, PlayerID: alice}, nil)
+	}
+
+	room.Guess(alice, "alice", "definitely wrong")
+	c.Advance(10 * time.Second)
+	if err := room.Pause(alice); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(time.Hour)
+	if err := room.Resume(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Advance(19 * time.Second)
+	if errorCode(canGuessNow()) != ErrCodeGuessCooldown {
+		t.Fatal("the pause used up the guess cooldown")
+	}
+	c.Advance(time.Second)
+	if err := canGuessNow(); err != nil {
+		t.Fatalf("cooldown did not end 30s of play after the guess: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package models
+
+import "time"
+
+const ErrCodeNotYourTurn = "not_your_turn"
+
+// pickTurnLocked первый ещё угадывающий игрок, начиная с позиции start.
+// Вызывается под dataMu.
+func (r *Room) pickTurnLocked(start int) string {
+	for i := range r.Players {
+		player := r.Players[(start+i)%len(r.Players)]
+		if player.inGame() {
+			return player.ID
+		}
+	}
+	return ""
+}
+
+// setTurnLocked назначает активного игрока и запускает его таймер хода,
+// возвращает true, если игрок сменился. Вызывается под dataMu.
+func (r *Room) setTurnLocked(playerID string) bool {
+	if r.ActivePlayerID == playerID {
+		return false
+	}
+	r.ActivePlayerID = playerID
+	r.startTurnTimerLocked(playerID)
+	return true
+}
+
+func (r *Room) broadcastTurn(playerID string) {
+	player := r.GetPlayer(playerID)
+	if player == nil {
+		return
+	}
+
+	r.sendMessageToAll(WSTurnChangedResponse{
+		Type:       "turn_changed",
+		PlayerID:   player.ID,
+		PlayerName: player.Name,
+		Text:       "It's " + player.Name + "'s turn",
+		Timestamp:  time.Now().Unix(),
+	})
+}
+
+// startTurns отдаёт первый ход в начале угадывания
+func (r *Room) startTurns() {
+	r.dataMu.Lock()
+	if !r.Settings.TurnMode || r.Phase != PhaseGuessing || len(r.Players) == 0 {
+		r.dataMu.Unlock()
+		return
+	}
+	active := r.pickTurnLocked(0)
+	changed := r.setTurnLocked(active)
+	r.dataMu.Unlock()
+
+	if changed {
+		r.broadcastTurn(active)
+	}
+}
+
+// passTurnLocked передаёт ход следующему после from, если ход сейчас
+// у from. Возвращает нового активного игрока, если он сменился.
+// Вызывается под dataMu.
+func (r *Room) passTurnLocked(from string) (string, bool) {
+	if !r.Settings.TurnMode || r.Phase != PhaseGuessing || r.ActivePlayerID != from {
+		return "", false
+	}
+	next := r.pickTurnLocked(r.findPlayerById(from) + 1)
+	return next, r.setTurnLocked(next)
+}
+
+// passTurn передаёт ход следующему после from, если ход сейчас у from
+func (r *Room) passTurn(from string) {
+	r.dataMu.Lock()
+	next, changed := r.passTurnLocked(from)
+	r.dataMu.Unlock()
+
+	if changed {
+		r.broadcastTurn(next)
+	}
+}
+
+// passTurnOnQuestion передаёт ход автора после ответа «нет» на его вопрос.
+// Один вопрос передаёт ход не больше одного раза, повторные «нет» и
+// ответы после смены хода ничего не меняют.
+func (r *Room) passTurnOnQuestion(questionID string) {
+	r.dataMu.Lock()
+	index := r.findQuestionLocked(questionID)
+	if index == -1 || r.Questions[index].Resolved {
+		r.dataMu.Unlock()
+		return
+	}
+	// Вопрос решён, даже если ход уже ушёл от автора: иначе старый
+	// вопрос передал бы следующий ход того же игрока
+	r.Questions[index].Resolved = true
+	next, changed := r.passTurnLocked(r.Questions[index].PlayerID)
+	r.dataMu.Unlock()
+
+	if changed {
+		r.broadcastTurn(next)
+	}
+}
+
+// passTurnFromIndex передаёт ход, когда активный игрок покинул комнату,
+// index его бывшая позиция в Players
+func (r *Room) passTurnFromIndex(removedID string, index int) {
+	r.dataMu.Lock()
+	if !r.Settings.TurnMode || r.Phase != PhaseGuessing || r.ActivePlayerID != removedID || len(r.Players) == 0 {
+		r.dataMu.Unlock()
+		return
+	}
+	next := r.pickTurnLocked(index)
+	changed := r.setTurnLocked(next)
+	r.dataMu.Unlock()
+
+	if changed {
+		r.broadcastTurn(next)
+	}
+}
+
+// onTurn проверка для команд, которые в режиме ходов доступны только активному игроку
+func onTurn[T any]() func(*CommandContext, *T) error {
+	return func(ctx *CommandContext, _ *T) error {
+		room := ctx.Room
+		room.dataMu.RLock()
+		defer room.dataMu.RUnlock()
+
+		if room.Settings.TurnMode && room.ActivePlayerID != ctx.PlayerID {
+			return &CommandError{
+				Code:    ErrCodeNotYourTurn,
+				Message: "it is not your turn",
+			}
+		}
+		return nil
+	}
+}
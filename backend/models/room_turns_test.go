@@ -0,0 +1,115 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// startTurnMode переводит комнату сразу в угадывание в режиме ходов
+func startTurnMode(t *testing.T, room *Room, active string) {
+	t.Helper()
+	room.dataMu.Lock()
+	room.Settings.TurnMode = true
+	room.Phase = PhaseGuessing
+	room.setTurnLocked(active)
+	room.dataMu.Unlock()
+}
+
+func activePlayer(room *Room) string {
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+	return room.ActivePlayerID
+}
+
+func TestNoPassesTurnOncePerQuestion(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob", "carol")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+	carol := playerID(t, room, "carol")
+	startTurnMode(t, room, alice)
+
+	question := room.AskQuestion(alice, "alice", "Am I real?", time.Now().Unix())
+
+	if _, err := room.AnswerQuestion(bob, "bob", question.ID, AnswerNo, ""); err != nil {
+		t.Fatal(err)
+	}
+	room.passTurnOnQuestion(question.ID)
+	if got := activePlayer(room); got != bob {
+		t.Fatalf("after the first no active = %s, want bob", got)
+	}
+
+	// Второе «нет» на тот же вопрос ход не трогает
+	if _, err := room.AnswerQuestion(carol, "carol", question.ID, AnswerNo, ""); err != nil {
+		t.Fatal(err)
+	}
+	room.passTurnOnQuestion(question.ID)
+	if got := activePlayer(room); got != bob {
+		t.Fatalf("after the second no active = %s, want bob", got)
+	}
+}
+
+func TestStaleQuestionDoesNotPassLaterTurn(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	alice := playerID(t, room, "alice")
+	startTurnMode(t, room, alice)
+
+	question := room.AskQuestion(alice, "alice", "Am I real?", time.Now().Unix())
+	room.passTurnOnQuestion(question.ID)
+	room.passTurn(playerID(t, room, "bob"))
+	if got := activePlayer(room); got != alice {
+		t.Fatalf("active = %s, want alice back", got)
+	}
+
+	room.passTurnOnQuestion(question.ID)
+	if got := activePlayer(room); got != alice {
+		t.Fatalf("old question passed alice's new turn to %s", got)
+	}
+}
+
+func TestMajorityNoPassesTurn(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob", "carol")
+	alice, bob := playerID(t, room, "alice"), playerID(t, room, "bob")
+	carol := playerID(t, room, "carol")
+	startTurnMode(t, room, alice)
+	room.dataMu.Lock()
+	room.Settings.MajorityVote = true
+	room.dataMu.Unlock()
+	// Голосуют только подключённые игроки
+	for _, id := range []string{alice, bob, carol} {
+		connectFake(t, room, id)
+	}
+
+	question := room.AskQuestion(alice, "alice", "Am I real?", time.Now().Unix())
+	answer := func(id, name string, value AnswerValue) error {
+		msg := fmt.Sprintf(`{"type":"answer","questionId":%q,"answer":%q}`, question.ID, value)
+		return room.HandleMessage(id, name, []byte(msg))
+	}
+
+	if err := answer(bob, "bob", AnswerNo); err != nil {
+		t.Fatal(err)
+	}
+	if got := activePlayer(room); got != alice {
+		t.Fatalf("turn passed to %s before the vote closed", got)
+	}
+	if err := answer(carol, "carol", AnswerNo); err != nil {
+		t.Fatal(err)
+	}
+	if got := activePlayer(room); got != bob {
+		t.Fatalf("after the vote resolved to no active = %s, want bob", got)
+	}
+}
+
+func TestAnswerRejectedWhilePaused(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	alice := playerID(t, room, "alice")
+	startTurnMode(t, room, alice)
+
+	question := room.AskQuestion(alice, "alice", "Am I real?", time.Now().Unix())
+	if err := room.Pause(alice); err != nil {
+		t.Fatal(err)
+	}
+	msg := fmt.Sprintf(`{"type":"answer","questionId":%q,"answer":"no"}`, question.ID)
+	if err := room.HandleMessage(playerID(t, room, "bob"), "bob", []byte(msg)); errorCode(err) != ErrCodePaused {
+		t.Fatalf("answer while paused: err = %v, want %s", err, ErrCodePaused)
+	}
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DefaultVoteTimeout сколько длится голосование, если таймаут не задан
+const DefaultVoteTimeout = 30 * time.Second
+
+// QuestionVote голосование по вопросу в режиме большинства
+type QuestionVote struct {
+	// Voters игроки, которые были в сети, когда вопрос задали, кроме автора
+	// и зрителей
+	Voters []string    `json:"voters"`
+	Closed bool        `json:"closed"`
+	Result AnswerValue `json:"result,omitempty"`
+
+	// Deadline когда голосование закроется по таймауту, по часам clock.
+	// Клиенты видят его как closesAt в Unix-секундах.
+	Deadline time.Time `json:"deadline"`
+}
+
+func (v QuestionVote) closesAt() int64 {
+	return v.Deadline.Unix()
+}
+
+// MarshalJSON добавляет производное closesAt
+func (v QuestionVote) MarshalJSON() ([]byte, error) {
+	type vote QuestionVote
+	return json.Marshal(struct {
+		vote
+		ClosesAt int64 `json:"closesAt"`
+	}{vote(v), v.closesAt()})
+}
+
+func (v *QuestionVote) isVoter(playerID string) bool {
+	for _, id := range v.Voters {
+		if id == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// newVote голосование для вопроса playerID, nil если режим выключен
+func (r *Room) newVote(playerID string) *QuestionVote {
+	r.dataMu.RLock()
+	enabled, timeout := r.Settings.MajorityVote, r.Settings.voteTimeout()
+	r.dataMu.RUnlock()
+	if !enabled {
+		return nil
+	}
+
+	connected := r.connectedIDs()
+
+	r.dataMu.RLock()
+	defer r.dataMu.RUnlock()
+
+	voters := make([]string, 0, len(r.Players))
+	for _, player := range r.Players {
+		if player.ID != playerID && !player.IsSpectator && connected[player.ID] {
+			voters = append(voters, player.ID)
+		}
+	}
+	return &QuestionVote{
+		Voters:   voters,
+		Deadline: clock.Now().Add(timeout),
+	}
+}
+
+// scheduleVoteLocked закрывает голосование по таймауту. Вызывается под dataMu.
+func (r *Room) scheduleVoteLocked(q Question) {
+	if q.Vote == nil || q.Vote.Closed || r.Paused {
+		return
+	}
+
+	r.voteTimers[q.ID] = clock.AfterFunc(q.Vote.Deadline.Sub(clock.Now()), func() {
+		r.closeVote(q.ID, true)
+	})
+}
+
+// voteCompleteLocked все ли голосующие ответили. Вызывается под dataMu.
+func voteCompleteLocked(q *Question) bool {
+	for _, id := range q.Vote.Voters {
+		if _, answered := q.Answers[id]; !answered {
+			return false
+		}
+	}
+	return true
+}
+
+// majority ответ, набравший больше всего голосов. При равенстве итог maybe,
+// без голосов итог пустой.
+func majority(tally map[AnswerValue]int) AnswerValue {
+	var result AnswerValue
+	best, tie := 0, false
+	for _, value := range answerValues {
+		switch count := tally[value]; {
+		case count > best:
+			result, best, tie = value, count, false
+		case count == best && count > 0:
+			tie = true
+		}
+	}
+	if tie {
+		return AnswerMaybe
+	}
+	return result
+}
+
+// closeVote подводит итог голосования и рассылает его. В режиме ходов
+// итоговое «нет» передаёт ход.
+func (r *Room) closeVote(questionID string, timedOut bool) {
+	r.dataMu.Lock()
+	index := r.findQuestionLocked(questionID)
+	if index == -1 || r.Questions[index].Vote == nil || r.Questions[index].Vote.Closed || (timedOut && r.Paused) {
+		r.dataMu.Unlock()
+		return
+	}
+
+	if timer, exists := r.voteTimers[questionID]; exists {
+		timer.Stop()
+		delete(r.voteTimers, questionID)
+	}
+
+	question := &r.Questions[index]
+	question.Vote.Closed = true
+	question.Vote.Result = majority(question.Tally)
+	result := question.clone()
+	r.dataMu.Unlock()
+
+	text := "No votes for: " + result.Text
+	if result.Vote.Result != "" {
+		text = result.Text + " — " + string(result.Vote.Result)
+	}
+
+	r.sendMessageToAll(WSQuestionResolvedResponse{
+		Type:       "question_resolved",
+		QuestionID: result.ID,
+		PlayerID:   result.PlayerID,
+		Answer:     result.Vote.Result,
+		Tally:      result.Tally,
+		Votes:      len(result.Answers),
+		Voters:     len(result.Vote.Voters),
+		TimedOut:   timedOut,
+		Text:       text,
+		Timestamp:  time.Now().Unix(),
+	})
+
+	if result.Vote.Result == AnswerNo {
+		r.passTurnOnQuestion(result.ID)
+	}
+}
+
+// stopVoteTimersLocked останавливает все голосования. Вызывается под dataMu.
+func (r *Room) stopVoteTimersLocked() {
+	for _, timer := range r.voteTimers {
+		timer.Stop()
+	}
+	r.voteTimers = make(map[string]Timer)
+}
+
+// resetQuestionsLocked очищает вопросы перед новым раундом. Вызывается под dataMu.
+func (r *Room) resetQuestionsLocked() {
+	r.stopVoteTimersLocked()
+	r.Questions = nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestVoteClosesAtIsDerived(t *testing.T) {
+	deadline := time.Unix(1700000000, 500)
+	data, err := json.Marshal(&QuestionVote{Voters: []string{"p1"}, Deadline: deadline})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var client struct {
+		ClosesAt int64 `json:"closesAt"`
+	}
+	if err := json.Unmarshal(data, &client); err != nil {
+		t.Fatal(err)
+	}
+	if client.ClosesAt != deadline.Unix() {
+		t.Errorf("closesAt = %d, want %d", client.ClosesAt, deadline.Unix())
+	}
+
+	// Из сохранённой комнаты дедлайн восстанавливается без потери точности
+	var restored QuestionVote
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Deadline.Equal(deadline) {
+		t.Errorf("restored deadline = %v, want %v", restored.Deadline, deadline)
+	}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+var sessionSecret = mustRandomBytes(32)
+
+// SetSessionSecret задаёт ключ подписи токенов. Без него ключ случайный
+// и выданные токены перестают работать после перезапуска.
+func SetSessionSecret(secret []byte) {
+	sessionSecret = secret
+}
+
+func mustRandomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// newPlayerID генерирует непрозрачный идентификатор игрока
+func newPlayerID() string {
+	return hex.EncodeToString(mustRandomBytes(16))
+}
+
+func newSessionNonce() string {
+	return hex.EncodeToString(mustRandomBytes(8))
+}
+
+// sessionTokenLocked подписывает комнату, игрока и его nonce ключом
+// сервера. Без nonce токена нет, пустая строка. Вызывается под dataMu.
+func (r *Room) sessionTokenLocked(playerID string) string {
+	nonce := r.SessionNonces[playerID]
+	if nonce == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, sessionSecret)
+	mac.Write([]byte(r.Code))
+	mac.Write([]byte{0})
+	mac.Write([]byte(playerID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(nonce))
+	// Смена пароля отзывает все сессии, подключённым игрокам токены
+	// выдаются заново
+	if r.PasswordHash != "" {
+		mac.Write([]byte{0})
+		mac.Write([]byte("password"))
+		mac.Write([]byte{0})
+		mac.Write([]byte(r.PasswordHash))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SessionToken токен сессии игрока. Действует, пока игрок в комнате,
+// его сессию не отозвали и не сменился пароль комнаты.
+func (r *Room) SessionToken(playerID string) string {
+	r.dataMu.RLock()
+	defer r.dataMu.RUnlock()
+	return r.sessionTokenLocked(playerID)
+}
+
+// VerifySession проверяет токен и то, что игрок всё ещё в комнате
+func (r *Room) VerifySession(playerID, token string) bool {
+	if playerID == "" || token == "" {
+		return false
+	}
+
+	r.dataMu.RLock()
+	defer r.dataMu.RUnlock()
+
+	if r.findPlayerById(playerID) == -1 {
+		return false
+	}
+	// Игроку без nonce сессия не выдавалась, ему придётся войти заново
+	expected := r.sessionTokenLocked(playerID)
+	if expected == "" {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(expected))
+}
+
+// renewSessions выдаёт подключённым игрокам новые токены после смены
+// пароля. Отключённым игрокам придётся войти в комнату заново.
+func (r *Room) renewSessions() {
+	connected := r.connectedIDs()
+
+	r.dataMu.RLock()
+	tokens := make(map[string]string, len(connected))
+	for id := range connected {
+		tokens[id] = r.sessionTokenLocked(id)
+	}
+	r.dataMu.RUnlock()
+
+	now := time.Now().Unix()
+	r.connMu.RLock()
+	defer r.connMu.RUnlock()
+	for id, token := range tokens {
+		if pc, exists := r.Connections[id]; exists {
+			pc.enqueue(WSSessionTokenResponse{
+				Type:      "session_token",
+				Token:     token,
+				Timestamp: now,
+			})
+		}
+	}
+}
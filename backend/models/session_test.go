@@ -0,0 +1,146 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+// newTestRoom комната вне хранилища, закрывается по окончании теста
+func newTestRoom(t *testing.T, players ...string) *Room {
+	t.Helper()
+	room := newRoom("TEST01", time.Now())
+	t.Cleanup(room.Close)
+
+	for _, name := range players {
+		if _, err := room.AddPlayer(name, false); err != nil {
+			t.Fatalf("AddPlayer(%q): %v", name, err)
+		}
+	}
+	return room
+}
+
+func playerID(t *testing.T, room *Room, name string) string {
+	t.Helper()
+	room.dataMu.RLock()
+	defer room.dataMu.RUnlock()
+
+	index := room.findPlayerByName(name)
+	if index == -1 {
+		t.Fatalf("player %q not found", name)
+	}
+	return room.Players[index].ID
+}
+
+func TestVerifySession(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	other := newTestRoom(t, "alice")
+	other.Code = "TEST02"
+
+	alice := playerID(t, room, "alice")
+	bob := playerID(t, room, "bob")
+	token := room.SessionToken(alice)
+	tampered := "A" + token[1:]
+	if tampered == token {
+		tampered = "B" + token[1:]
+	}
+
+	tests := []struct {
+		name     string
+		playerID string
+		token    string
+		want     bool
+	}{
+		{"own token", alice, token, true},
+		{"other player's token", bob, token, false},
+		{"empty token", alice, "", false},
+		{"empty player", "", token, false},
+		{"unknown player", "nobody", room.SessionToken("nobody"), false},
+		{"tampered token", alice, tampered, false},
+		{"token from another room", alice, other.SessionToken(playerID(t, other, "alice")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := room.VerifySession(tt.playerID, tt.token); got != tt.want {
+				t.Errorf("VerifySession() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySessionAfterRemoval(t *testing.T) {
+	room := newTestRoom(t, "alice", "bob")
+	bob := playerID(t, room, "bob")
+	token := room.SessionToken(bob)
+
+	room.RemovePlayer(bob)
+	if room.VerifySession(bob, token) {
+		t.Fatal("removed player's token is still valid")
+	}
+
+	// Вернувшийся игрок получает новый ID, старый токен к нему не подходит
+	rejoined, err := room.AddPlayer("bob", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room.VerifySession(rejoined.ID, token) {
+		t.Fatal("old token is valid for the rejoined player")
+	}
+	if !room.VerifySession(rejoined.ID, room.SessionToken(rejoined.ID)) {
+		t.Fatal("new token is not valid")
+	}
+}
+
+func TestSessionTokenDependsOnNonce(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	alice := playerID(t, room, "alice")
+	token := room.SessionToken(alice)
+
+	room.dataMu.Lock()
+	room.SessionNonces[alice] = newSessionNonce()
+	room.dataMu.Unlock()
+
+	if room.VerifySession(alice, token) {
+		t.Fatal("token is still valid after the nonce changed")
+	}
+}
+
+func TestSessionRequiresNonce(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	alice := playerID(t, room, "alice")
+
+	room.dataMu.Lock()
+	delete(room.SessionNonces, alice)
+	room.dataMu.Unlock()
+
+	if token := room.SessionToken(alice); token != "" {
+		t.Fatalf("token issued without a nonce: %q", token)
+	}
+	legacy := hmac.New(sha256.New, sessionSecret)
+	legacy.Write([]byte(room.Code + "\x00" + alice))
+	if room.VerifySession(alice, base64.RawURLEncoding.EncodeToString(legacy.Sum(nil))) {
+		t.Fatal("token without a nonce is still accepted")
+	}
+}
+
+func TestSessionSurvivesRestore(t *testing.T) {
+	room := newTestRoom(t, "alice")
+	alice := playerID(t, room, "alice")
+	token := room.SessionToken(alice)
+
+	rec, err := room.record()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := restoreRoom(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(restored.Close)
+
+	if !restored.VerifySession(alice, token) {
+		t.Fatal("token is not valid after restore")
+	}
+}
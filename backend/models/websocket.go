@@ -1,5 +1,7 @@
 package models
 
+import "encoding/json"
+
 // ============ БАЗОВЫЕ СТРУКТУРЫ ============
 
 type WSMessageBase struct {
@@ -22,10 +24,28 @@ type WSQuestionMessage struct {
 	Text       string `json:"text"`
 }
 
+// WSAnswerMessage ответ на вопрос questionId. Старые клиенты присылают
+// только text, тогда ответ относится к последнему вопросу.
+type WSAnswerMessage struct {
+	Type       string      `json:"type"`
+	QuestionID string      `json:"questionId"`
+	Answer     AnswerValue `json:"answer"`
+	Text       string      `json:"text,omitempty"`
+}
+
 type WSSetCharacterMessage struct {
-	Type      string `json:"type"`
-	PlayerID  string `json:"playerId,omitempty"`
-	Character string `json:"character"`
+	Type        string   `json:"type"`
+	PlayerID    string   `json:"playerId,omitempty"`
+	Character   string   `json:"character"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+type WSSuggestCharacterMessage struct {
+	Type       string `json:"type"`
+	Count      int    `json:"count,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
 }
 
 type WSAddWinnerMessage struct {
@@ -47,8 +67,33 @@ type WSGuessMessage struct {
 	Character string `json:"character"`
 }
 
+type WSEndGameMessage struct {
+	Type string `json:"type"`
+}
+
+type WSPauseMessage struct {
+	Type string `json:"type"`
+}
+
+type WSResumeMessage struct {
+	Type string `json:"type"`
+}
+
+type WSRematchMessage struct {
+	Type    string `json:"type"`
+	Shuffle bool   `json:"shuffle"`
+}
+
+// WSUpdateSettingsMessage частичное изменение настроек комнаты,
+// поля, которых нет в settings, не меняются
+type WSUpdateSettingsMessage struct {
+	Type     string          `json:"type"`
+	Settings json.RawMessage `json:"settings"`
+}
+
 type WSMovePlayerMessage struct {
 	Type       string `json:"type"`
+	PlayerID   string `json:"playerId,omitempty"`
 	PlayerName string `json:"playerName"`
 	Index      int    `json:"index"`
 }
@@ -69,6 +114,21 @@ type WSLeaveResponse struct {
 	Timestamp  int64  `json:"timestamp"`
 }
 
+// WSResyncRequiredResponse пропущенных событий уже нет в журнале, клиенту
+// нужно заново получить комнату через GET /api/room/:code
+type WSResyncRequiredResponse struct {
+	Type      string `json:"type"`
+	LastSeq   int64  `json:"lastSeq"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type WSPresenceResponse struct {
+	Type      string        `json:"type"`
+	PlayerID  string        `json:"playerId"`
+	State     PresenceState `json:"state"`
+	Timestamp int64         `json:"timestamp"`
+}
+
 type WSChatResponse struct {
 	Type       string `json:"type"`
 	PlayerID   string `json:"playerId"`
@@ -78,27 +138,63 @@ type WSChatResponse struct {
 }
 
 type WSQuestionResponse struct {
-	Type       string `json:"type"`
-	PlayerID   string `json:"playerId"`
-	PlayerName string `json:"playerName"`
-	Text       string `json:"text"`
-	Timestamp  int64  `json:"timestamp"`
+	Type       string   `json:"type"`
+	QuestionID string   `json:"questionId"`
+	PlayerID   string   `json:"playerId"`
+	PlayerName string   `json:"playerName"`
+	Text       string   `json:"text"`
+	Voters     []string `json:"voters,omitempty"`
+	ClosesAt   int64    `json:"closesAt,omitempty"`
+	Timestamp  int64    `json:"timestamp"`
 }
 
 type WSAnswerResponse struct {
-	Type       string `json:"type"`
-	PlayerID   string `json:"playerId"`
-	PlayerName string `json:"playerName"`
-	Text       string `json:"text"`
-	Timestamp  int64  `json:"timestamp"`
+	Type       string      `json:"type"`
+	QuestionID string      `json:"questionId"`
+	PlayerID   string      `json:"playerId"`
+	PlayerName string      `json:"playerName"`
+	Answer     AnswerValue `json:"answer"`
+	Text       string      `json:"text"`
+	Timestamp  int64       `json:"timestamp"`
+}
+
+type WSAnswerTallyResponse struct {
+	Type       string              `json:"type"`
+	QuestionID string              `json:"questionId"`
+	Tally      map[AnswerValue]int `json:"tally"`
+	Answered   int                 `json:"answered"`
+	Timestamp  int64               `json:"timestamp"`
+}
+
+type WSQuestionResolvedResponse struct {
+	Type       string              `json:"type"`
+	QuestionID string              `json:"questionId"`
+	PlayerID   string              `json:"playerId"`
+	Answer     AnswerValue         `json:"answer"`
+	Tally      map[AnswerValue]int `json:"tally"`
+	Votes      int                 `json:"votes"`
+	Voters     int                 `json:"voters"`
+	TimedOut   bool                `json:"timedOut"`
+	Text       string              `json:"text"`
+	Timestamp  int64               `json:"timestamp"`
 }
 
 type WSSetCharacterResponse struct {
 	Type      string `json:"type"`
 	PlayerID  string `json:"playerId"`
 	Character string `json:"character"` // "?" для владельца персонажа
-	Text      string `json:"text"`
-	Timestamp int64  `json:"timestamp"`
+	// Aliases и Description владельцу не отправляются
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Text        string   `json:"text"`
+	Timestamp   int64    `json:"timestamp"`
+}
+
+// WSCharacterSuggestionsResponse отправляется только загадывающему
+type WSCharacterSuggestionsResponse struct {
+	Type       string      `json:"type"`
+	Candidates []DeckEntry `json:"candidates"`
+	Timestamp  int64       `json:"timestamp"`
 }
 
 type WSAddWinnerResponse struct {
@@ -116,28 +212,121 @@ type WSPlayerRemovedResponse struct {
 	Timestamp  int64  `json:"timestamp"`
 }
 
+type WSPhaseChangedResponse struct {
+	Type      string    `json:"type"`
+	Phase     GamePhase `json:"phase"`
+	Previous  GamePhase `json:"previous"`
+	Timestamp int64     `json:"timestamp"`
+}
+
+type WSTurnChangedResponse struct {
+	Type       string `json:"type"`
+	PlayerID   string `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	Text       string `json:"text"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+type WSTimerTickResponse struct {
+	Type            string `json:"type"`
+	ActivePlayerID  string `json:"activePlayerId"`
+	TurnRemainingMs int64  `json:"turnRemainingMs"`
+	GameRemainingMs int64  `json:"gameRemainingMs"`
+	Timestamp       int64  `json:"timestamp"`
+}
+
+type WSTimerExpiredResponse struct {
+	Type      string `json:"type"`
+	Timer     string `json:"timer"`
+	PlayerID  string `json:"playerId,omitempty"`
+	Text      string `json:"text"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type WSPauseResponse struct {
+	Type      string `json:"type"`
+	Paused    bool   `json:"paused"`
+	PausedAt  int64  `json:"pausedAt,omitempty"`
+	PlayerID  string `json:"playerId"`
+	Text      string `json:"text"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// WSSessionTokenResponse новый токен сессии после смены пароля комнаты
+type WSSessionTokenResponse struct {
+	Type      string `json:"type"`
+	Token     string `json:"token"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type WSSettingsUpdatedResponse struct {
+	Type      string       `json:"type"`
+	Settings  RoomSettings `json:"settings"`
+	Timestamp int64        `json:"timestamp"`
+}
+
+type WSHostChangedResponse struct {
+	Type      string `json:"type"`
+	HostID    string `json:"hostId"`
+	HostName  string `json:"hostName"`
+	Text      string `json:"text"`
+	Timestamp int64  `json:"timestamp"`
+}
+
 type WSGameStartedResponse struct {
 	Type      string `json:"type"`
 	Text      string `json:"text"`
 	Timestamp int64  `json:"timestamp"`
 }
 
+type WSGameOverResponse struct {
+	Type          string                   `json:"type"`
+	Reason        string                   `json:"reason"`
+	Ranking       []RankingEntry           `json:"ranking"`
+	Scoreboard    []ScoreEntry             `json:"scoreboard"`
+	Characters    map[string]string        `json:"characters"`
+	CharacterInfo map[string]CharacterInfo `json:"characterInfo"`
+	Timestamp     int64                    `json:"timestamp"`
+}
+
+type WSRematchResponse struct {
+	Type       string       `json:"type"`
+	Players    []Player     `json:"players"`
+	Scoreboard []ScoreEntry `json:"scoreboard"`
+	Round      int          `json:"round"`
+	Text       string       `json:"text"`
+	Timestamp  int64        `json:"timestamp"`
+}
+
 type WSPongResponse struct {
-	Type      string `json:"type"`
+	Type string `json:"type"`
 }
 
 type WSGuessResultResponse struct {
-	Type       string `json:"type"`
-	PlayerID   string `json:"playerId"`
-	PlayerName string `json:"playerName"`
-	Character  string `json:"character"`
-	Correct    bool   `json:"correct"`
-	Text       string `json:"text"`
-	Timestamp  int64  `json:"timestamp"`
+	Type         string      `json:"type"`
+	PlayerID     string      `json:"playerId"`
+	PlayerName   string      `json:"playerName"`
+	Character    string      `json:"character"`
+	Correct      bool        `json:"correct"`
+	Match        MatchResult `json:"match"`
+	WrongGuesses int         `json:"wrongGuesses"`
+	Text         string      `json:"text"`
+	Timestamp    int64       `json:"timestamp"`
+}
+
+type WSEliminatedResponse struct {
+	Type         string `json:"type"`
+	PlayerID     string `json:"playerId"`
+	PlayerName   string `json:"playerName"`
+	Character    string `json:"character"`
+	WrongGuesses int    `json:"wrongGuesses"`
+	Text         string `json:"text"`
+	Timestamp    int64  `json:"timestamp"`
 }
 
 type WSErrorResponse struct {
 	Type      string `json:"type"`
+	Code      string `json:"code,omitempty"`
 	Error     string `json:"error"`
 	Timestamp int64  `json:"timestamp"`
 }
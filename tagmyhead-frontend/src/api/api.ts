@@ -1,29 +1,46 @@
-import type { Room, Player, CreateRoomResponse } from '../types'
+import type { Room, JoinRoomResponse, CreateRoomResponse } from '../types'
+import { authHeaders, saveToken } from '../utils/session'
 
 const API_BASE = '/api'
 
 export class API {
-    static async createRoom(): Promise<CreateRoomResponse> {
+    static async createRoom(name: string): Promise<CreateRoomResponse> {
         const res = await fetch(`${API_BASE}/room/create`, {
             method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                Accept: 'application/json',
+            },
+            body: JSON.stringify({ name }),
         })
         if (!res.ok) throw new Error('Failed to create room')
-        return res.json()
+
+        const room: CreateRoomResponse = await res.json()
+
+        saveToken(room.code, room.player.token)
+
+        return room
     }
 
     static async getRoom(code: string, playerId: string): Promise<Room> {
-        const res = await fetch(`${API_BASE}/room/${code}?playerId=${playerId}`)
+        const res = await fetch(
+            `${API_BASE}/room/${code}?playerId=${playerId}`,
+            { headers: authHeaders(code) }
+        )
         if (!res.ok) throw new Error('Room not found')
         return res.json()
     }
 
     static async hasRoom(code: string): Promise<boolean> {
-        const res = await fetch(`${API_BASE}/room/${code}?playerId=a`)
+        const res = await fetch(`${API_BASE}/room/${code}/exists`)
 
-        return res.status === 403
+        return res.ok
     }
 
-    static async joinRoom(code: string, name: string): Promise<Player> {
+    static async joinRoom(
+        code: string,
+        name: string
+    ): Promise<JoinRoomResponse> {
         const res = await fetch(`${API_BASE}/room/${code}/join`, {
             method: 'POST',
             headers: {
@@ -38,14 +55,21 @@ export class API {
             throw new Error(error.error || 'Failed to join room')
         }
 
-        const player = await res.json()
+        const player: JoinRoomResponse = await res.json()
+
+        saveToken(code, player.token)
 
         return player
     }
 
-    static async startGame(code: string): Promise<void> {
+    static async startGame(code: string, playerId: string): Promise<void> {
         const res = await fetch(`${API_BASE}/room/${code}/start`, {
             method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                ...authHeaders(code),
+            },
+            body: JSON.stringify({ playerId }),
         })
         if (!res.ok) {
             const error = await res.json()
@@ -1,5 +1,6 @@
 import type { GameState, WSMessage } from '../types'
 import { log } from '../utils/log'
+import { getToken, saveToken } from '../utils/session'
 
 type MessageHandler = (msg: WSMessage | GameState) => void
 
@@ -16,7 +17,8 @@ export class GameWebSocket {
             const protocol =
                 window.location.protocol === 'https:' ? 'wss:' : 'ws:'
             const host = window.location.host
-            const url = `${protocol}//${host}/ws/${roomCode}/${playerId}`
+            const token = encodeURIComponent(getToken(roomCode))
+            const url = `${protocol}//${host}/ws/${roomCode}/${playerId}?token=${token}`
 
             log('Connecting to WebSocket:', url)
             this.ws = new WebSocket(url)
@@ -41,6 +43,12 @@ export class GameWebSocket {
                         return
                     }
 
+                    // Пароль комнаты сменился, прежний токен больше не действует
+                    if (msg.type === 'session_token' && msg.token) {
+                        saveToken(roomCode, msg.token)
+                        return
+                    }
+
                     this.emit(msg.type, msg)
                     this.emit('*', msg)
                 } catch (err) {
@@ -5,6 +5,7 @@ import { API } from '../api/api'
 import type { Room, WSMessage } from '../types'
 import { navigate } from '../router'
 import { log } from '../utils/log'
+import { removeToken } from '../utils/session'
 
 interface RouteContext {
     params: {
@@ -249,6 +250,7 @@ export class GamePage extends LitElement {
     private handleLeaveGame() {
         localStorage.removeItem(`playerId_${roomCode}`)
         localStorage.removeItem(`playerName_${roomCode}`)
+        removeToken(roomCode)
 
         log('Leave')
 
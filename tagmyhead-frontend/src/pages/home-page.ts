@@ -95,12 +95,12 @@ export class HomePage extends LitElement {
         this.requestUpdate()
 
         try {
-            const { code } = await API.createRoom()
-
-            localStorage.setItem(`playerName_${code}`, this.createName.trim())
-
-            const player = await API.joinRoom(code, this.createName.trim())
+            // Создатель входит в комнату сразу и становится хостом
+            const { code, player } = await API.createRoom(
+                this.createName.trim()
+            )
 
+            localStorage.setItem(`playerName_${code}`, player.name)
             localStorage.setItem(`playerId_${code}`, player.id)
 
             navigate(`/room/${code}`)
@@ -138,7 +138,7 @@ export class HomePage extends LitElement {
                         localStorage.setItem(`playerName_${code}`, name)
                         localStorage.setItem(`playerId_${code}`, id)
 
-                        const room = await API.getRoom(code, name)
+                        const room = await API.getRoom(code, id)
 
                         if (room.started) {
                             this.error = 'This game has already started'
@@ -7,6 +7,7 @@ import type { Room, WSMessage } from '../types'
 
 import '../components/join-room-form'
 import { log } from '../utils/log'
+import { removeToken } from '../utils/session'
 
 interface RouteContext {
     params: {
@@ -193,7 +194,7 @@ export class LobbyPage extends LitElement {
         }
 
         try {
-            await API.startGame(this.roomCode)
+            await API.startGame(this.roomCode, this.playerId)
         } catch (err) {
             this.error =
                 err instanceof Error ? err.message : 'Failed to start game'
@@ -207,6 +208,7 @@ export class LobbyPage extends LitElement {
 
         localStorage.removeItem(`playerId_${this.roomCode}`)
         localStorage.removeItem(`playerName_${this.roomCode}`)
+        removeToken(this.roomCode)
     }
 
     private handleRemovePlayer(e: CustomEvent) {
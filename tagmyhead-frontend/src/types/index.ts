@@ -4,6 +4,10 @@ export interface Player {
     isWinner?: boolean
 }
 
+export interface JoinRoomResponse extends Player {
+    token: string
+}
+
 export interface Room {
     code: string
     players: Player[]
@@ -34,6 +38,7 @@ export interface WSMessage {
         | 'guess_result'
         | 'game_state'
         | 'set_character'
+        | 'session_token'
     playerId: string
     removedId?: string
     winnerId?: string
@@ -41,9 +46,11 @@ export interface WSMessage {
     text?: string
     character?: string
     correct?: boolean
+    token?: string
     timestamp: number
 }
 
 export interface CreateRoomResponse {
     code: string
+    player: JoinRoomResponse
 }
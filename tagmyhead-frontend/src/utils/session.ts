@@ -0,0 +1,19 @@
+// Токен сессии выдаётся при входе в комнату, без него сервер не отдаёт
+// состояние комнаты и не открывает WebSocket
+const tokenKey = (code: string) => `playerToken_${code}`
+
+export const saveToken = (code: string, token: string) => {
+    localStorage.setItem(tokenKey(code), token)
+}
+
+export const getToken = (code: string) => {
+    return localStorage.getItem(tokenKey(code)) || ''
+}
+
+export const removeToken = (code: string) => {
+    localStorage.removeItem(tokenKey(code))
+}
+
+export const authHeaders = (code: string): Record<string, string> => ({
+    Authorization: `Bearer ${getToken(code)}`,
+})